@@ -0,0 +1,449 @@
+package bundle
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/contexts"
+)
+
+const currentVersion = 1
+
+var ErrSensitiveEnv = errors.New("bundle sets sensitive environment variables")
+
+// Tokens are only present in encrypted bundles.
+type Bundle struct {
+	Version  int     `json:"version" yaml:"version"`
+	Contexts []Entry `json:"contexts" yaml:"contexts"`
+}
+
+type Entry struct {
+	config.Context `yaml:",inline"`
+	Token          string            `json:"token,omitempty" yaml:"token,omitempty"`
+	HTTPPassword   string            `json:"http_auth_password,omitempty" yaml:"http_auth_password,omitempty"`
+	SecretValues   map[string]string `json:"secret_env_values,omitempty" yaml:"secret_env_values,omitempty"`
+}
+
+type Strategy string
+
+const (
+	StrategySkip      Strategy = "skip"
+	StrategyOverwrite Strategy = "overwrite"
+	StrategyRename    Strategy = "rename"
+)
+
+func ParseStrategy(value string) (Strategy, error) {
+	switch Strategy(strings.ToLower(strings.TrimSpace(value))) {
+	case StrategySkip:
+		return StrategySkip, nil
+	case StrategyOverwrite:
+		return StrategyOverwrite, nil
+	case StrategyRename:
+		return StrategyRename, nil
+	default:
+		return "", fmt.Errorf("unknown conflict strategy %q (want skip, overwrite or rename)", value)
+	}
+}
+
+type Report struct {
+	Added          []string
+	Overwritten    []string
+	Skipped        []string
+	Renamed        map[string]string
+	SecretsRemoved []string
+	SensitiveEnv   []string
+}
+
+// Export adds the ancestors of the named contexts so derived contexts resolve
+// wherever the bundle is imported.
+func Export(mgr *contexts.Manager, names []string) (*Bundle, error) {
+	all, _, err := mgr.List()
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*config.Context, len(all))
+	for _, ctx := range all {
+		byName[ctx.Name] = ctx
+	}
+
+	selected := all
+	if len(names) > 0 {
+		selected = make([]*config.Context, 0, len(names))
+		for _, name := range names {
+			ctx, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", contexts.ErrContextNotFound, name)
+			}
+			selected = append(selected, ctx)
+		}
+	}
+
+	included := make(map[string]struct{}, len(selected))
+	for _, ctx := range selected {
+		included[ctx.Name] = struct{}{}
+	}
+	for i := 0; i < len(selected); i++ {
+		parent := selected[i].Parent
+		if parent == "" {
+			continue
+		}
+		if _, ok := included[parent]; ok {
+			continue
+		}
+		ctx, ok := byName[parent]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s (parent of %s)", contexts.ErrContextNotFound, parent, selected[i].Name)
+		}
+		included[parent] = struct{}{}
+		selected = append(selected, ctx)
+	}
+
+	b := &Bundle{Version: currentVersion, Contexts: make([]Entry, 0, len(selected))}
+	for _, ctx := range selected {
+		entry := Entry{Context: *ctx}
+		// Binary paths are machine-local; only a pinned version is portable.
+		entry.NomadPath = ""
+		b.Contexts = append(b.Contexts, entry)
+	}
+	return b, nil
+}
+
+func Encode(w io.Writer, b *Bundle, format config.Format) error {
+	if b.hasSecrets() {
+		return errors.New("bundle contains tokens and must be encrypted")
+	}
+
+	data, err := marshal(b, format)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func marshal(b *Bundle, format config.Format) ([]byte, error) {
+	if format == config.FormatTOML {
+		return nil, errors.New("bundles can be written as json or yaml")
+	}
+	return config.Marshal(b, format)
+}
+
+func (b *Bundle) hasSecrets() bool {
+	for _, entry := range b.Contexts {
+		if entry.Token != "" || entry.HTTPPassword != "" || len(entry.SecretValues) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func Decode(data []byte) (*Bundle, error) {
+	var b Bundle
+	if err := config.Unmarshal(data, &b, config.FormatYAML); err != nil {
+		return nil, fmt.Errorf("parse bundle: %w", err)
+	}
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (b *Bundle) validate() error {
+	if b.Version != currentVersion {
+		return fmt.Errorf("unsupported bundle version %d", b.Version)
+	}
+
+	seen := make(map[string]struct{}, len(b.Contexts))
+	for i, entry := range b.Contexts {
+		name := strings.TrimSpace(entry.Name)
+		if name == "" {
+			return fmt.Errorf("bundle entry %d has no name", i+1)
+		}
+		if err := contexts.ValidateName(name); err != nil {
+			return fmt.Errorf("bundle entry %d: %w", i+1, err)
+		}
+		if strings.TrimSpace(entry.Address) == "" && strings.TrimSpace(entry.Parent) == "" {
+			return fmt.Errorf("bundle entry %q has no address or parent", name)
+		}
+		if _, dup := seen[name]; dup {
+			return fmt.Errorf("bundle contains %q more than once", name)
+		}
+		seen[name] = struct{}{}
+	}
+	return nil
+}
+
+func Import(mgr *contexts.Manager, b *Bundle, strategy Strategy, allowSensitive bool) (*Report, error) {
+	existing, _, err := mgr.List()
+	if err != nil {
+		return nil, err
+	}
+
+	taken := make(map[string]struct{}, len(existing)+len(b.Contexts))
+	for _, ctx := range existing {
+		taken[ctx.Name] = struct{}{}
+	}
+	for _, entry := range b.Contexts {
+		taken[entry.Name] = struct{}{}
+	}
+	conflicts := make(map[string]*config.Context, len(existing))
+	for _, ctx := range existing {
+		conflicts[ctx.Name] = ctx
+	}
+	if err := checkParents(b.Contexts, conflicts, strategy); err != nil {
+		return nil, err
+	}
+	sensitive := sensitiveEnv(b.Contexts, conflicts, strategy)
+	if len(sensitive) > 0 && !allowSensitive {
+		return nil, fmt.Errorf("%w: %s", ErrSensitiveEnv, strings.Join(sensitive, ", "))
+	}
+
+	report := &Report{Renamed: map[string]string{}, SensitiveEnv: sensitive}
+	for _, entry := range parentsFirst(b.Contexts) {
+		name := entry.Name
+		var replaced *config.Context
+		if current, conflict := conflicts[name]; conflict {
+			switch strategy {
+			case StrategySkip:
+				report.Skipped = append(report.Skipped, name)
+				continue
+			case StrategyRename:
+				renamed := freeName(name, taken)
+				taken[renamed] = struct{}{}
+				report.Renamed[name] = renamed
+				name = renamed
+			case StrategyOverwrite:
+				report.Overwritten = append(report.Overwritten, name)
+				replaced = current
+			default:
+				return report, fmt.Errorf("unknown conflict strategy %q", strategy)
+			}
+		} else {
+			report.Added = append(report.Added, name)
+		}
+
+		imported := entry.Context
+		imported.Name = name
+		// A bundle must not choose which local binary proxied commands run.
+		imported.NomadPath = ""
+		if replaced != nil {
+			imported.NomadPath = replaced.NomadPath
+		}
+		if renamed, ok := report.Renamed[imported.Parent]; ok {
+			imported.Parent = renamed
+		}
+		// Secret variables without a value cannot be used on this machine.
+		imported.SecretEnv = nil
+		if replaced != nil {
+			if replaced.Address != imported.Address || replaced.Parent != imported.Parent {
+				// Keeping the stored secrets would send them to wherever the
+				// bundle points the context now.
+				if err := mgr.DeleteSecrets(replaced); err != nil {
+					return report, err
+				}
+				report.SecretsRemoved = append(report.SecretsRemoved, name)
+				for _, child := range addressInheritors(existing, name) {
+					credentials := *child
+					credentials.SecretEnv = nil
+					if err := mgr.DeleteSecrets(&credentials); err != nil {
+						return report, err
+					}
+					report.SecretsRemoved = append(report.SecretsRemoved, child.Name)
+				}
+			} else {
+				imported.SecretEnv = keptSecretEnv(mgr, replaced, &imported)
+			}
+		}
+		if err := mgr.Put(&imported, entry.Token); err != nil {
+			return report, err
+		}
+		if entry.HTTPPassword != "" && imported.HTTPAuthUser != "" {
+			if err := mgr.SetHTTPPassword(name, entry.HTTPPassword); err != nil {
+				return report, err
+			}
+		}
+		for _, key := range entry.SecretEnv {
+			value, ok := entry.SecretValues[key]
+			if !ok {
+				continue
+			}
+			if err := mgr.SetSecretEnv(name, key, value); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	sort.Strings(report.Added)
+	sort.Strings(report.Overwritten)
+	sort.Strings(report.Skipped)
+	sort.Strings(report.SecretsRemoved)
+	report.SecretsRemoved = slices.Compact(report.SecretsRemoved)
+	return report, nil
+}
+
+func addressInheritors(existing []*config.Context, name string) []*config.Context {
+	var found []*config.Context
+	for _, ctx := range existing {
+		if ctx.Parent == name && ctx.Address == "" {
+			found = append(found, ctx)
+			found = append(found, addressInheritors(existing, ctx.Name)...)
+		}
+	}
+	return found
+}
+
+// keptSecretEnv deletes the values it drops so they do not linger unlisted in
+// the keyring.
+func keptSecretEnv(mgr *contexts.Manager, replaced, imported *config.Context) []string {
+	var kept []string
+	for _, key := range replaced.SecretEnv {
+		if _, plain := imported.Env[key]; !plain {
+			if _, err := mgr.SecretEnvValue(replaced.Name, key); err == nil {
+				kept = append(kept, key)
+				continue
+			}
+		}
+		_ = mgr.DeleteSecretEnv(replaced.Name, key)
+	}
+	return kept
+}
+
+// checkParents runs before anything is written so an import never stops
+// halfway on a missing parent.
+func checkParents(entries []Entry, existing map[string]*config.Context, strategy Strategy) error {
+	incoming := make(map[string]*config.Context, len(entries))
+	for i := range entries {
+		if _, conflict := existing[entries[i].Name]; conflict && strategy == StrategySkip {
+			continue
+		}
+		incoming[entries[i].Name] = &entries[i].Context
+	}
+	lookup := func(name string) (*config.Context, bool) {
+		if ctx, ok := incoming[name]; ok {
+			return ctx, true
+		}
+		ctx, ok := existing[name]
+		return ctx, ok
+	}
+
+	for _, entry := range entries {
+		ctx, _ := lookup(entry.Name)
+		if ctx == nil {
+			continue
+		}
+		if err := checkLocalParent(ctx, incoming, lookup); err != nil {
+			return err
+		}
+		seen := map[string]struct{}{ctx.Name: {}}
+		hasAddress := ctx.Address != ""
+		for ctx.Parent != "" {
+			parent, ok := lookup(ctx.Parent)
+			if !ok {
+				return fmt.Errorf("%w: %s (parent of %s); export it along with %s", contexts.ErrContextNotFound, ctx.Parent, ctx.Name, entry.Name)
+			}
+			if _, loop := seen[parent.Name]; loop {
+				return fmt.Errorf("%w: bundle entry %s", contexts.ErrParentCycle, entry.Name)
+			}
+			seen[parent.Name] = struct{}{}
+			hasAddress = hasAddress || parent.Address != ""
+			ctx = parent
+		}
+		if !hasAddress {
+			return fmt.Errorf("bundle entry %q has no address in its parent chain", entry.Name)
+		}
+	}
+	return nil
+}
+
+func sensitiveEnv(entries []Entry, existing map[string]*config.Context, strategy Strategy) []string {
+	var found []string
+	for _, entry := range entries {
+		if _, conflict := existing[entry.Name]; conflict && strategy == StrategySkip {
+			continue
+		}
+		keys := slices.Clone(entry.SecretEnv)
+		for key := range entry.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if contexts.SensitiveEnvKey(key) {
+				found = append(found, entry.Name+": "+key)
+			}
+		}
+	}
+	return found
+}
+
+// checkLocalParent stops an entry from inheriting a local context's
+// credentials for a host chosen by the bundle.
+func checkLocalParent(ctx *config.Context, incoming map[string]*config.Context, lookup func(string) (*config.Context, bool)) error {
+	if _, ok := incoming[ctx.Name]; !ok || ctx.Parent == "" || ctx.Address == "" {
+		return nil
+	}
+	if _, ok := incoming[ctx.Parent]; ok {
+		return nil
+	}
+
+	address := ""
+	seen := map[string]struct{}{}
+	for parent, ok := lookup(ctx.Parent); ok && address == ""; parent, ok = lookup(parent.Parent) {
+		if _, loop := seen[parent.Name]; loop {
+			break
+		}
+		seen[parent.Name] = struct{}{}
+		address = parent.Address
+	}
+	if address != "" && address != ctx.Address {
+		return fmt.Errorf("bundle entry %q sets address %s but its parent %q is a local context for %s; drop the parent or the address", ctx.Name, ctx.Address, ctx.Parent, address)
+	}
+	return nil
+}
+
+func parentsFirst(entries []Entry) []Entry {
+	pending := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		pending[entry.Name] = struct{}{}
+	}
+
+	ordered := make([]Entry, 0, len(entries))
+	for len(ordered) < len(entries) {
+		progressed := false
+		for _, entry := range entries {
+			if _, ok := pending[entry.Name]; !ok {
+				continue
+			}
+			if _, waiting := pending[entry.Parent]; waiting && entry.Parent != entry.Name {
+				continue
+			}
+			ordered = append(ordered, entry)
+			delete(pending, entry.Name)
+			progressed = true
+		}
+		if !progressed {
+			// A cycle; keep the remaining order and let the manager reject it.
+			for _, entry := range entries {
+				if _, ok := pending[entry.Name]; ok {
+					ordered = append(ordered, entry)
+				}
+			}
+			break
+		}
+	}
+	return ordered
+}
+
+func freeName(name string, taken map[string]struct{}) string {
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if _, ok := taken[candidate]; !ok {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,401 @@
+package bundle_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+
+	"github.com/brianmichel/nomad-context/internal/bundle"
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/contexts"
+)
+
+func TestExportExcludesTokensAndRoundTrips(t *testing.T) {
+	mgr := newTestManager(t)
+	if err := mgr.Upsert("dev", "https://dev", "dev-secret"); err != nil {
+		t.Fatalf("Upsert(dev) error = %v", err)
+	}
+	if err := mgr.Upsert("prod", "https://prod", ""); err != nil {
+		t.Fatalf("Upsert(prod) error = %v", err)
+	}
+
+	for _, format := range []config.Format{config.FormatJSON, config.FormatYAML} {
+		b, err := bundle.Export(mgr, []string{"dev"})
+		if err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := bundle.Encode(&buf, b, format); err != nil {
+			t.Fatalf("Encode(%s) error = %v", format, err)
+		}
+		if strings.Contains(buf.String(), "dev-secret") {
+			t.Fatalf("%s bundle leaked the token:\n%s", format, buf.String())
+		}
+
+		decoded, err := bundle.Decode(buf.Bytes())
+		if err != nil {
+			t.Fatalf("Decode(%s) error = %v", format, err)
+		}
+		if len(decoded.Contexts) != 1 || decoded.Contexts[0].Name != "dev" || decoded.Contexts[0].Address != "https://dev" {
+			t.Fatalf("unexpected %s bundle contents: %+v", format, decoded.Contexts)
+		}
+	}
+}
+
+func TestExportUnknownContext(t *testing.T) {
+	mgr := newTestManager(t)
+	if _, err := bundle.Export(mgr, []string{"missing"}); err == nil {
+		t.Fatalf("expected Export() to fail for unknown context")
+	}
+}
+
+func TestImportConflictStrategies(t *testing.T) {
+	incoming := &bundle.Bundle{
+		Version: 1,
+		Contexts: []bundle.Entry{
+			{Context: config.Context{Name: "dev", Address: "https://new-dev"}},
+			{Context: config.Context{Name: "stage", Address: "https://stage"}},
+		},
+	}
+
+	cases := []struct {
+		strategy bundle.Strategy
+		devAddr  string
+		extra    string
+	}{
+		{strategy: bundle.StrategySkip, devAddr: "https://dev"},
+		{strategy: bundle.StrategyOverwrite, devAddr: "https://new-dev"},
+		{strategy: bundle.StrategyRename, devAddr: "https://dev", extra: "dev-1"},
+	}
+
+	for _, tc := range cases {
+		t.Run(string(tc.strategy), func(t *testing.T) {
+			mgr := newTestManager(t)
+			if err := mgr.Upsert("dev", "https://dev", ""); err != nil {
+				t.Fatalf("Upsert(dev) error = %v", err)
+			}
+
+			report, err := bundle.Import(mgr, incoming, tc.strategy, false)
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+			if len(report.Added) != 1 || report.Added[0] != "stage" {
+				t.Fatalf("expected stage to be added, got %+v", report)
+			}
+
+			dev, err := mgr.Resolve("dev")
+			if err != nil {
+				t.Fatalf("Resolve(dev) error = %v", err)
+			}
+			if dev.Address != tc.devAddr {
+				t.Fatalf("dev address = %q, want %q", dev.Address, tc.devAddr)
+			}
+
+			if tc.extra != "" {
+				renamed, err := mgr.Resolve(tc.extra)
+				if err != nil {
+					t.Fatalf("Resolve(%s) error = %v", tc.extra, err)
+				}
+				if renamed.Address != "https://new-dev" {
+					t.Fatalf("renamed address = %q, want %q", renamed.Address, "https://new-dev")
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeRejectsInvalidBundles(t *testing.T) {
+	cases := map[string]string{
+		"version":   `{"version": 2, "contexts": []}`,
+		"no name":   `{"version": 1, "contexts": [{"address": "https://a"}]}`,
+		"duplicate": "version: 1\ncontexts:\n  - {name: a, address: https://a}\n  - {name: a, address: https://b}\n",
+	}
+
+	for name, data := range cases {
+		if _, err := bundle.Decode([]byte(data)); err == nil {
+			t.Fatalf("%s: expected Decode() to fail", name)
+		}
+	}
+}
+
+func newTestManager(t *testing.T) *contexts.Manager {
+	t.Helper()
+	t.Setenv("NOMAD_CONTEXT_HOME", t.TempDir())
+	keyring.MockInit()
+	return contexts.NewManager()
+}
+
+func TestImportOrdersParentsFirstAndFollowsRenames(t *testing.T) {
+	mgr := newTestManager(t)
+	if err := mgr.Upsert("prod", "https://old-prod", ""); err != nil {
+		t.Fatalf("Upsert(prod) error = %v", err)
+	}
+
+	incoming := &bundle.Bundle{
+		Version: 1,
+		Contexts: []bundle.Entry{
+			{Context: config.Context{Name: "prod/payments", Parent: "prod", Namespace: "payments"}},
+			{Context: config.Context{Name: "prod", Address: "https://prod"}},
+		},
+	}
+
+	report, err := bundle.Import(mgr, incoming, bundle.StrategyRename, false)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if report.Renamed["prod"] != "prod-1" {
+		t.Fatalf("expected prod to be renamed to prod-1, got %+v", report.Renamed)
+	}
+
+	child, err := mgr.Resolved("prod/payments")
+	if err != nil {
+		t.Fatalf("Resolved() error = %v", err)
+	}
+	if child.Context.Parent != "prod-1" || child.Context.Address != "https://prod" {
+		t.Fatalf("expected child to inherit from the renamed parent, got %+v", child.Context)
+	}
+}
+
+func TestImportOverwriteDropsSecretsWhenAddressChanges(t *testing.T) {
+	mgr := newTestManager(t)
+	if err := mgr.Put(&config.Context{Name: "prod", Address: "https://prod", HTTPAuthUser: "ops"}, "prod-token"); err != nil {
+		t.Fatalf("Put(prod) error = %v", err)
+	}
+	if err := mgr.SetHTTPPassword("prod", "hunter2"); err != nil {
+		t.Fatalf("SetHTTPPassword() error = %v", err)
+	}
+	if err := mgr.Put(&config.Context{Name: "prod/payments", Parent: "prod", Namespace: "payments"}, "payments-token"); err != nil {
+		t.Fatalf("Put(prod/payments) error = %v", err)
+	}
+	if err := mgr.Put(&config.Context{Name: "prod/edge", Parent: "prod", Address: "https://edge"}, "edge-token"); err != nil {
+		t.Fatalf("Put(prod/edge) error = %v", err)
+	}
+	if err := mgr.Upsert("dev", "https://dev", "dev-token"); err != nil {
+		t.Fatalf("Upsert(dev) error = %v", err)
+	}
+
+	incoming := &bundle.Bundle{
+		Version: 1,
+		Contexts: []bundle.Entry{
+			{Context: config.Context{Name: "prod", Address: "https://attacker.example", HTTPAuthUser: "ops"}},
+			{Context: config.Context{Name: "dev", Address: "https://dev", Namespace: "web"}},
+		},
+	}
+
+	report, err := bundle.Import(mgr, incoming, bundle.StrategyOverwrite, false)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if strings.Join(report.SecretsRemoved, ",") != "prod,prod/payments" {
+		t.Fatalf("SecretsRemoved = %v, want [prod prod/payments]", report.SecretsRemoved)
+	}
+
+	if _, err := mgr.Token("prod"); !errors.Is(err, contexts.ErrTokenNotFound) {
+		t.Fatalf("Token(prod) error = %v, want ErrTokenNotFound", err)
+	}
+	if _, err := mgr.HTTPPassword("prod"); !errors.Is(err, contexts.ErrSecretNotFound) {
+		t.Fatalf("HTTPPassword(prod) error = %v, want ErrSecretNotFound", err)
+	}
+	if _, err := mgr.Token("prod/payments"); !errors.Is(err, contexts.ErrTokenNotFound) {
+		t.Fatalf("Token(prod/payments) error = %v; it inherits the changed address", err)
+	}
+	if token, err := mgr.Token("prod/edge"); err != nil || token != "edge-token" {
+		t.Fatalf("Token(prod/edge) = %q, %v; it has its own address so it should be kept", token, err)
+	}
+	if token, err := mgr.Token("dev"); err != nil || token != "dev-token" {
+		t.Fatalf("Token(dev) = %q, %v; the address did not change so it should be kept", token, err)
+	}
+}
+
+func TestImportOverwriteKeepsStoredSecretEnv(t *testing.T) {
+	mgr := newTestManager(t)
+	if err := mgr.Upsert("prod", "https://prod", ""); err != nil {
+		t.Fatalf("Upsert(prod) error = %v", err)
+	}
+	for key, value := range map[string]string{"DB_PASSWORD": "s3cret", "API_KEY": "k3y"} {
+		if err := mgr.SetSecretEnv("prod", key, value); err != nil {
+			t.Fatalf("SetSecretEnv(%s) error = %v", key, err)
+		}
+	}
+
+	incoming := &bundle.Bundle{
+		Version: 1,
+		Contexts: []bundle.Entry{
+			{Context: config.Context{Name: "prod", Address: "https://prod", Namespace: "web", Env: map[string]string{"API_KEY": "public"}}},
+		},
+	}
+	if _, err := bundle.Import(mgr, incoming, bundle.StrategyOverwrite, false); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	stored, err := mgr.Resolve("prod")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if strings.Join(stored.SecretEnv, ",") != "DB_PASSWORD" {
+		t.Fatalf("SecretEnv = %v, want [DB_PASSWORD]", stored.SecretEnv)
+	}
+	if value, err := mgr.SecretEnvValue("prod", "DB_PASSWORD"); err != nil || value != "s3cret" {
+		t.Fatalf("SecretEnvValue(DB_PASSWORD) = %q, %v", value, err)
+	}
+	if _, err := mgr.SecretEnvValue("prod", "API_KEY"); !errors.Is(err, contexts.ErrSecretNotFound) {
+		t.Fatalf("SecretEnvValue(API_KEY) error = %v; the bundle set it in the clear", err)
+	}
+}
+
+func TestImportRejectsLocalParentWithForeignAddress(t *testing.T) {
+	mgr := newTestManager(t)
+	if err := mgr.Upsert("prod", "https://prod", "prod-token"); err != nil {
+		t.Fatalf("Upsert(prod) error = %v", err)
+	}
+
+	incoming := &bundle.Bundle{
+		Version: 1,
+		Contexts: []bundle.Entry{
+			{Context: config.Context{Name: "prod/payments", Parent: "prod", Address: "https://attacker.example"}},
+		},
+	}
+	if _, err := bundle.Import(mgr, incoming, bundle.StrategySkip, false); err == nil {
+		t.Fatalf("Import() expected an error for a foreign address under a local parent")
+	}
+	if _, err := mgr.Resolve("prod/payments"); !errors.Is(err, contexts.ErrContextNotFound) {
+		t.Fatalf("Resolve() error = %v, want nothing imported", err)
+	}
+
+	incoming.Contexts[0].Address = "https://prod"
+	if _, err := bundle.Import(mgr, incoming, bundle.StrategySkip, false); err != nil {
+		t.Fatalf("Import() with the parent's address error = %v", err)
+	}
+}
+
+func TestExportIncludesAncestors(t *testing.T) {
+	mgr := newTestManager(t)
+	if err := mgr.Put(&config.Context{Name: "prod", Address: "https://prod"}, "prod-token"); err != nil {
+		t.Fatalf("Put(prod) error = %v", err)
+	}
+	if err := mgr.Put(&config.Context{Name: "prod/payments", Parent: "prod", Namespace: "payments"}, ""); err != nil {
+		t.Fatalf("Put(prod/payments) error = %v", err)
+	}
+
+	b, err := bundle.Export(mgr, []string{"prod/payments"})
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if len(b.Contexts) != 2 || b.Contexts[0].Name != "prod/payments" || b.Contexts[1].Name != "prod" {
+		t.Fatalf("expected the parent to be exported too, got %+v", b.Contexts)
+	}
+	if err := bundle.IncludeSecrets(mgr, b); err != nil {
+		t.Fatalf("IncludeSecrets() error = %v", err)
+	}
+
+	fresh := newTestManager(t)
+	if _, err := bundle.Import(fresh, b, bundle.StrategySkip, false); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	token, from, err := fresh.EffectiveToken("prod/payments")
+	if err != nil || token != "prod-token" || from != "prod" {
+		t.Fatalf("EffectiveToken() = %q, %q, %v", token, from, err)
+	}
+}
+
+func TestImportChecksParentsBeforeWriting(t *testing.T) {
+	mgr := newTestManager(t)
+
+	incoming := &bundle.Bundle{
+		Version: 1,
+		Contexts: []bundle.Entry{
+			{Context: config.Context{Name: "dev", Address: "https://dev"}},
+			{Context: config.Context{Name: "prod/payments", Parent: "prod", Namespace: "payments"}},
+		},
+	}
+	if _, err := bundle.Import(mgr, incoming, bundle.StrategySkip, false); !errors.Is(err, contexts.ErrContextNotFound) {
+		t.Fatalf("Import() error = %v, want ErrContextNotFound", err)
+	}
+
+	list, _, err := mgr.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 0 {
+		t.Fatalf("expected nothing to be imported, got %d contexts", len(list))
+	}
+
+	if err := mgr.Upsert("prod", "https://prod", ""); err != nil {
+		t.Fatalf("Upsert(prod) error = %v", err)
+	}
+	if _, err := bundle.Import(mgr, incoming, bundle.StrategySkip, false); err != nil {
+		t.Fatalf("Import() with an existing parent error = %v", err)
+	}
+}
+
+func TestDecodeRejectsSecretAccountNames(t *testing.T) {
+	data := `{"version": 1, "contexts": [{"name": "prod:http-auth", "address": "https://evil"}]}`
+	if _, err := bundle.Decode([]byte(data)); err == nil {
+		t.Fatalf("expected Decode() to reject a context name with a colon")
+	}
+}
+
+func TestBundleIgnoresNomadPath(t *testing.T) {
+	mgr := newTestManager(t)
+	if err := mgr.Put(&config.Context{Name: "prod", Address: "https://prod", NomadPath: "/opt/nomad/bin/nomad", NomadVersion: "1.9.3"}, ""); err != nil {
+		t.Fatalf("Put(prod) error = %v", err)
+	}
+
+	b, err := bundle.Export(mgr, []string{"prod"})
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if b.Contexts[0].NomadPath != "" || b.Contexts[0].NomadVersion != "1.9.3" {
+		t.Fatalf("exported entry = %+v, want the version without the path", b.Contexts[0].Context)
+	}
+
+	incoming := &bundle.Bundle{
+		Version: 1,
+		Contexts: []bundle.Entry{
+			{Context: config.Context{Name: "prod", Address: "https://prod", NomadPath: "/tmp/evil"}},
+			{Context: config.Context{Name: "dev", Address: "https://dev", NomadPath: "/tmp/evil"}},
+		},
+	}
+	if _, err := bundle.Import(mgr, incoming, bundle.StrategyOverwrite, false); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	for name, want := range map[string]string{"prod": "/opt/nomad/bin/nomad", "dev": ""} {
+		stored, err := mgr.Resolve(name)
+		if err != nil {
+			t.Fatalf("Resolve(%s) error = %v", name, err)
+		}
+		if stored.NomadPath != want {
+			t.Fatalf("%s nomad path = %q, want %q", name, stored.NomadPath, want)
+		}
+	}
+}
+
+func TestImportRefusesSensitiveEnv(t *testing.T) {
+	mgr := newTestManager(t)
+
+	incoming := &bundle.Bundle{
+		Version: 1,
+		Contexts: []bundle.Entry{
+			{Context: config.Context{Name: "prod", Address: "https://prod", Env: map[string]string{"LD_PRELOAD": "/tmp/x.so", "NOMAD_SKIP_VERIFY": "true", "TEAM": "web"}}},
+		},
+	}
+	_, err := bundle.Import(mgr, incoming, bundle.StrategySkip, false)
+	if !errors.Is(err, bundle.ErrSensitiveEnv) || !strings.Contains(err.Error(), "prod: LD_PRELOAD, prod: NOMAD_SKIP_VERIFY") {
+		t.Fatalf("Import() error = %v, want ErrSensitiveEnv listing both keys", err)
+	}
+	if _, err := mgr.Resolve("prod"); !errors.Is(err, contexts.ErrContextNotFound) {
+		t.Fatalf("Resolve() error = %v, want nothing imported", err)
+	}
+
+	report, err := bundle.Import(mgr, incoming, bundle.StrategySkip, true)
+	if err != nil {
+		t.Fatalf("Import() with sensitive env allowed error = %v", err)
+	}
+	if len(report.SensitiveEnv) != 2 {
+		t.Fatalf("SensitiveEnv = %v, want both keys reported", report.SensitiveEnv)
+	}
+}
@@ -0,0 +1,137 @@
+package bundle
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/contexts"
+)
+
+// Bundles with secrets must be encrypted with Seal before they leave the
+// process.
+func IncludeSecrets(mgr *contexts.Manager, b *Bundle) error {
+	for i := range b.Contexts {
+		entry := &b.Contexts[i]
+
+		token, err := mgr.Token(entry.Name)
+		if err != nil && !errors.Is(err, contexts.ErrTokenNotFound) {
+			return err
+		}
+		entry.Token = token
+
+		if entry.HTTPAuthUser != "" {
+			password, err := mgr.HTTPPassword(entry.Name)
+			if err != nil && !errors.Is(err, contexts.ErrSecretNotFound) {
+				return err
+			}
+			entry.HTTPPassword = password
+		}
+
+		for _, key := range entry.SecretEnv {
+			value, err := mgr.SecretEnvValue(entry.Name, key)
+			if err != nil {
+				if errors.Is(err, contexts.ErrSecretNotFound) {
+					continue
+				}
+				return err
+			}
+			if entry.SecretValues == nil {
+				entry.SecretValues = make(map[string]string)
+			}
+			entry.SecretValues[key] = value
+		}
+	}
+	return nil
+}
+
+func EncodeSealed(w io.Writer, b *Bundle, format config.Format, recipients ...age.Recipient) error {
+	plaintext, err := marshal(b, format)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := Seal(plaintext, recipients...)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sealed)
+	return err
+}
+
+func DecodeSealed(data []byte, identities ...age.Identity) (*Bundle, error) {
+	plaintext, err := Open(data, identities...)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(plaintext)
+}
+
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header))
+}
+
+func Seal(plaintext []byte, recipients ...age.Recipient) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("at least one recipient or a passphrase is required")
+	}
+
+	var buf bytes.Buffer
+	armored := armor.NewWriter(&buf)
+	w, err := age.Encrypt(armored, recipients...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if err := armored.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func Open(data []byte, identities ...age.Identity) ([]byte, error) {
+	r, err := age.Decrypt(armor.NewReader(bytes.NewReader(bytes.TrimSpace(data))), identities...)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt bundle: %w", err)
+	}
+	return io.ReadAll(r)
+}
+
+func ParseRecipients(values []string) ([]age.Recipient, error) {
+	recipients := make([]age.Recipient, 0, len(values))
+	for _, value := range values {
+		recipient, err := age.ParseX25519Recipient(strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, nil
+}
+
+func LoadIdentities(path string) ([]age.Identity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	identities, err := age.ParseIdentities(bufio.NewReader(f))
+	if err != nil {
+		return nil, fmt.Errorf("parse identities in %s: %w", path, err)
+	}
+	return identities, nil
+}
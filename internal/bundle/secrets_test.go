@@ -0,0 +1,100 @@
+package bundle_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+
+	"github.com/brianmichel/nomad-context/internal/bundle"
+	"github.com/brianmichel/nomad-context/internal/config"
+)
+
+func TestSealedBundleCarriesTokens(t *testing.T) {
+	source := newTestManager(t)
+	if err := source.Upsert("prod", "https://prod", "prod-secret"); err != nil {
+		t.Fatalf("Upsert(prod) error = %v", err)
+	}
+	if err := source.Upsert("dev", "https://dev", ""); err != nil {
+		t.Fatalf("Upsert(dev) error = %v", err)
+	}
+
+	b, err := bundle.Export(source, nil)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if err := bundle.IncludeSecrets(source, b); err != nil {
+		t.Fatalf("IncludeSecrets() error = %v", err)
+	}
+
+	if err := bundle.Encode(&bytes.Buffer{}, b, config.FormatJSON); err == nil {
+		t.Fatalf("expected Encode() to refuse a bundle with tokens")
+	}
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("GenerateX25519Identity() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := bundle.EncodeSealed(&buf, b, config.FormatYAML, identity.Recipient()); err != nil {
+		t.Fatalf("EncodeSealed() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "prod-secret") || !bundle.IsEncrypted(buf.Bytes()) {
+		t.Fatalf("expected an encrypted bundle, got:\n%s", buf.String())
+	}
+
+	other, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("GenerateX25519Identity() error = %v", err)
+	}
+	if _, err := bundle.DecodeSealed(buf.Bytes(), other); err == nil {
+		t.Fatalf("expected DecodeSealed() to fail with the wrong identity")
+	}
+
+	decoded, err := bundle.DecodeSealed(buf.Bytes(), identity)
+	if err != nil {
+		t.Fatalf("DecodeSealed() error = %v", err)
+	}
+
+	target := newTestManager(t)
+	if _, err := bundle.Import(target, decoded, bundle.StrategySkip, false); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	token, err := target.Token("prod")
+	if err != nil {
+		t.Fatalf("Token(prod) error = %v", err)
+	}
+	if token != "prod-secret" {
+		t.Fatalf("Token(prod) = %q, want %q", token, "prod-secret")
+	}
+}
+
+func TestSealWithPassphrase(t *testing.T) {
+	recipient, err := age.NewScryptRecipient("correct horse")
+	if err != nil {
+		t.Fatalf("NewScryptRecipient() error = %v", err)
+	}
+	recipient.SetWorkFactor(10)
+
+	sealed, err := bundle.Seal([]byte("payload"), recipient)
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+
+	wrong, _ := age.NewScryptIdentity("battery staple")
+	if _, err := bundle.Open(sealed, wrong); err == nil {
+		t.Fatalf("expected Open() to fail with the wrong passphrase")
+	}
+
+	identity, _ := age.NewScryptIdentity("correct horse")
+	plaintext, err := bundle.Open(sealed, identity)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if string(plaintext) != "payload" {
+		t.Fatalf("Open() = %q, want %q", plaintext, "payload")
+	}
+}
@@ -0,0 +1,182 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/contexts"
+)
+
+const maxCatalogSize = 4 << 20
+
+type Catalog struct {
+	Contexts []Entry `json:"contexts"`
+}
+
+type Entry struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+type Report struct {
+	Added          []string
+	Updated        []string
+	Unchanged      []string
+	Removed        []string
+	Conflicts      []string
+	LocalConflicts []string
+}
+
+func Fetch(ctx context.Context, client *http.Client, source string) ([]byte, error) {
+	u, err := url.Parse(source)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		path := source
+		if err == nil && u.Scheme == "file" {
+			path = u.Path
+		}
+		return readFile(path)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s: unexpected status %s", source, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCatalogSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxCatalogSize {
+		return nil, fmt.Errorf("fetch %s: catalog exceeds %d bytes", source, maxCatalogSize)
+	}
+	return data, nil
+}
+
+func readFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxCatalogSize {
+		return nil, fmt.Errorf("%s: catalog exceeds %d bytes", path, maxCatalogSize)
+	}
+	return os.ReadFile(path)
+}
+
+func Parse(data []byte) (*Catalog, error) {
+	var cat Catalog
+	if err := config.Unmarshal(data, &cat, config.FormatJSON); err != nil {
+		return nil, fmt.Errorf("parse catalog: %w", err)
+	}
+	if err := cat.Validate(); err != nil {
+		return nil, err
+	}
+	return &cat, nil
+}
+
+func (c *Catalog) Validate() error {
+	if len(c.Contexts) == 0 {
+		return errors.New("catalog defines no contexts")
+	}
+
+	seen := make(map[string]struct{}, len(c.Contexts))
+	for i, entry := range c.Contexts {
+		name := strings.TrimSpace(entry.Name)
+		if name == "" {
+			return fmt.Errorf("catalog entry %d has no name", i+1)
+		}
+		if err := contexts.ValidateName(name); err != nil {
+			return fmt.Errorf("catalog entry %d: %w", i+1, err)
+		}
+		if _, dup := seen[name]; dup {
+			return fmt.Errorf("catalog defines %q more than once", name)
+		}
+		seen[name] = struct{}{}
+
+		u, err := url.Parse(strings.TrimSpace(entry.Address))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("catalog entry %q has invalid address %q", name, entry.Address)
+		}
+	}
+	return nil
+}
+
+// Locally created contexts keep their stored token, so they are only taken
+// over when adopt is set. Contexts that left the catalog are flagged rather
+// than deleted.
+func Reconcile(cfg *config.Config, cat *Catalog, source string, adopt bool) *Report {
+	report := &Report{}
+	listed := make(map[string]struct{}, len(cat.Contexts))
+
+	for _, entry := range cat.Contexts {
+		name := strings.TrimSpace(entry.Name)
+		address := strings.TrimSpace(entry.Address)
+		listed[name] = struct{}{}
+
+		existing, ok := cfg.Contexts[name]
+		if !ok {
+			cfg.Contexts[name] = &config.Context{Name: name, Address: address, Catalog: source}
+			report.Added = append(report.Added, name)
+			continue
+		}
+
+		if existing.Catalog != "" && existing.Catalog != source {
+			report.Conflicts = append(report.Conflicts, name)
+			continue
+		}
+		if existing.Catalog == "" && !adopt {
+			report.LocalConflicts = append(report.LocalConflicts, name)
+			continue
+		}
+
+		if existing.Address == address && existing.Catalog == source && !existing.CatalogRemoved {
+			report.Unchanged = append(report.Unchanged, name)
+			continue
+		}
+
+		existing.Address = address
+		existing.Catalog = source
+		existing.CatalogRemoved = false
+		report.Updated = append(report.Updated, name)
+	}
+
+	for name, ctx := range cfg.Contexts {
+		if ctx.Catalog != source {
+			continue
+		}
+		if _, ok := listed[name]; ok {
+			continue
+		}
+		ctx.CatalogRemoved = true
+		report.Removed = append(report.Removed, name)
+	}
+
+	if cfg.Current == "" && len(report.Added) > 0 {
+		sort.Strings(report.Added)
+		cfg.Current = report.Added[0]
+	}
+
+	for _, names := range [][]string{report.Added, report.Updated, report.Unchanged, report.Removed, report.Conflicts, report.LocalConflicts} {
+		sort.Strings(names)
+	}
+	return report
+}
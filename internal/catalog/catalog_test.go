@@ -0,0 +1,140 @@
+package catalog_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/catalog"
+	"github.com/brianmichel/nomad-context/internal/config"
+)
+
+const catalogJSON = `{
+  "contexts": [
+    {"name": "prod", "address": "https://prod.nomad:4646"},
+    {"name": "stage", "address": "https://stage.nomad:4646"}
+  ]
+}`
+
+func TestFetchOverHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/catalog.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(catalogJSON))
+	}))
+	defer srv.Close()
+
+	data, err := catalog.Fetch(context.Background(), srv.Client(), srv.URL+"/catalog.json")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	cat, err := catalog.Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(cat.Contexts) != 2 {
+		t.Fatalf("expected 2 catalog entries, got %d", len(cat.Contexts))
+	}
+
+	if _, err := catalog.Fetch(context.Background(), srv.Client(), srv.URL+"/missing"); err == nil {
+		t.Fatalf("expected Fetch() to fail for a missing catalog")
+	}
+}
+
+func TestFetchFromPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	if err := os.WriteFile(path, []byte(catalogJSON), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	for _, source := range []string{path, "file://" + path} {
+		data, err := catalog.Fetch(context.Background(), http.DefaultClient, source)
+		if err != nil {
+			t.Fatalf("Fetch(%s) error = %v", source, err)
+		}
+		if string(data) != catalogJSON {
+			t.Fatalf("Fetch(%s) returned unexpected data", source)
+		}
+	}
+}
+
+func TestParseRejectsInvalidCatalogs(t *testing.T) {
+	cases := map[string]string{
+		"empty":     `{"contexts": []}`,
+		"no name":   `{"contexts": [{"address": "https://a"}]}`,
+		"duplicate": `{"contexts": [{"name": "a", "address": "https://a"}, {"name": "a", "address": "https://b"}]}`,
+		"address":   `{"contexts": [{"name": "a", "address": "nomad.local:4646"}]}`,
+		"colon":     `{"contexts": [{"name": "prod:http-auth", "address": "https://a"}]}`,
+	}
+
+	for name, data := range cases {
+		if _, err := catalog.Parse([]byte(data)); err == nil {
+			t.Fatalf("%s: expected Parse() to fail", name)
+		}
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	const source = "https://platform.example/catalog.json"
+
+	cfg := &config.Config{
+		Current: "local",
+		Contexts: map[string]*config.Context{
+			"local": {Name: "local", Address: "http://127.0.0.1:4646"},
+			"prod":  {Name: "prod", Address: "https://old-prod:4646"},
+			"gone":  {Name: "gone", Address: "https://gone:4646", Catalog: source},
+			"stage": {Name: "stage", Address: "https://other:4646", Catalog: "https://other.example/catalog.json"},
+		},
+	}
+
+	cat := &catalog.Catalog{Contexts: []catalog.Entry{
+		{Name: "prod", Address: "https://prod:4646"},
+		{Name: "stage", Address: "https://stage:4646"},
+		{Name: "qa", Address: "https://qa:4646"},
+	}}
+
+	report := catalog.Reconcile(cfg, cat, source, false)
+
+	want := &catalog.Report{
+		Added:          []string{"qa"},
+		Removed:        []string{"gone"},
+		Conflicts:      []string{"stage"},
+		LocalConflicts: []string{"prod"},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Fatalf("Reconcile() report = %+v, want %+v", report, want)
+	}
+	if got := cfg.Contexts["prod"]; got.Address != "https://old-prod:4646" || got.Catalog != "" {
+		t.Fatalf("expected local prod to be left alone without adopt: %+v", got)
+	}
+
+	report = catalog.Reconcile(cfg, cat, source, true)
+	if !reflect.DeepEqual(report.Updated, []string{"prod"}) || len(report.LocalConflicts) != 0 {
+		t.Fatalf("expected adopt to update prod, got %+v", report)
+	}
+
+	if got := cfg.Contexts["prod"]; got.Address != "https://prod:4646" || got.Catalog != source {
+		t.Fatalf("prod not updated: %+v", got)
+	}
+	if !cfg.Contexts["gone"].CatalogRemoved {
+		t.Fatalf("expected gone to be flagged as removed")
+	}
+	if cfg.Contexts["stage"].Address != "https://other:4646" {
+		t.Fatalf("expected stage owned by another catalog to be left alone")
+	}
+	if cfg.Contexts["local"].Catalog != "" {
+		t.Fatalf("expected local context to be untouched")
+	}
+
+	report = catalog.Reconcile(cfg, cat, source, false)
+	if len(report.Unchanged) != 2 || len(report.Added)+len(report.Updated) != 0 {
+		t.Fatalf("expected second reconcile to be a no-op, got %+v", report)
+	}
+}
@@ -0,0 +1,87 @@
+package catalog
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const signatureSuffix = ".sig"
+
+var (
+	ErrNoTrustedKeys = errors.New("no trusted catalog keys configured")
+	ErrUnsigned      = errors.New("catalog is not signed")
+	ErrBadSignature  = errors.New("catalog signature does not match any trusted key")
+)
+
+func SignatureSource(source string) string {
+	u, err := url.Parse(source)
+	if err == nil && (u.Scheme == "http" || u.Scheme == "https" || u.Scheme == "file") {
+		u.Path += signatureSuffix
+		u.RawPath = ""
+		return u.String()
+	}
+	return source + signatureSuffix
+}
+
+func Verify(data, signature []byte, trusted map[string]string) (string, error) {
+	if len(trusted) == 0 {
+		return "", ErrNoTrustedKeys
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return "", fmt.Errorf("%w: malformed signature", ErrBadSignature)
+	}
+
+	names := make([]string, 0, len(trusted))
+	for name := range trusted {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		key, err := ParsePublicKey(trusted[name])
+		if err != nil {
+			return "", fmt.Errorf("trusted key %q: %w", name, err)
+		}
+		if ed25519.Verify(key, data, sig) {
+			return name, nil
+		}
+	}
+	return "", ErrBadSignature
+}
+
+func Sign(data []byte, key ed25519.PrivateKey) []byte {
+	sig := ed25519.Sign(key, data)
+	return []byte(base64.StdEncoding.EncodeToString(sig) + "\n")
+}
+
+func GenerateKey() (string, string, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.StdEncoding.EncodeToString(pub), base64.StdEncoding.EncodeToString(priv), nil
+}
+
+func ParsePublicKey(value string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid ed25519 public key")
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+func ParsePrivateKey(value string) (ed25519.PrivateKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil || len(raw) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid ed25519 private key")
+	}
+	return ed25519.PrivateKey(raw), nil
+}
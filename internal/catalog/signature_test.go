@@ -0,0 +1,75 @@
+package catalog_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/catalog"
+)
+
+func TestVerifySignature(t *testing.T) {
+	pub, priv, err := catalog.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	otherPub, otherPriv, err := catalog.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	key, err := catalog.ParsePrivateKey(priv)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey() error = %v", err)
+	}
+	otherKey, err := catalog.ParsePrivateKey(otherPriv)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey() error = %v", err)
+	}
+
+	data := []byte(catalogJSON)
+	sig := catalog.Sign(data, key)
+	trusted := map[string]string{"platform": pub}
+
+	signer, err := catalog.Verify(data, sig, trusted)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if signer != "platform" {
+		t.Fatalf("Verify() signer = %q, want %q", signer, "platform")
+	}
+
+	cases := map[string]struct {
+		data    []byte
+		sig     []byte
+		trusted map[string]string
+		want    error
+	}{
+		"no trusted keys": {data: data, sig: sig, want: catalog.ErrNoTrustedKeys},
+		"tampered":        {data: []byte(`{"contexts": []}`), sig: sig, trusted: trusted, want: catalog.ErrBadSignature},
+		"untrusted key":   {data: data, sig: catalog.Sign(data, otherKey), trusted: trusted, want: catalog.ErrBadSignature},
+		"malformed":       {data: data, sig: []byte("not-a-signature"), trusted: trusted, want: catalog.ErrBadSignature},
+	}
+	for name, tc := range cases {
+		if _, err := catalog.Verify(tc.data, tc.sig, tc.trusted); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: Verify() error = %v, want %v", name, err, tc.want)
+		}
+	}
+
+	trusted["rotated"] = otherPub
+	if signer, err := catalog.Verify(data, catalog.Sign(data, otherKey), trusted); err != nil || signer != "rotated" {
+		t.Fatalf("Verify() with second key = %q, %v", signer, err)
+	}
+}
+
+func TestSignatureSource(t *testing.T) {
+	cases := map[string]string{
+		"https://example.com/catalog.json?ref=main": "https://example.com/catalog.json.sig?ref=main",
+		"/srv/platform/catalog.json":                "/srv/platform/catalog.json.sig",
+		"file:///srv/platform/catalog.json":         "file:///srv/platform/catalog.json.sig",
+	}
+	for source, want := range cases {
+		if got := catalog.SignatureSource(source); got != want {
+			t.Fatalf("SignatureSource(%q) = %q, want %q", source, got, want)
+		}
+	}
+}
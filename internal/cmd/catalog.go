@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/brianmichel/nomad-context/internal/catalog"
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/contexts"
+)
+
+var errDryRun = errors.New("dry run")
+
+func newCatalogCommand(mgr *contexts.Manager) *cobra.Command {
+	catalogCmd := &cobra.Command{
+		Use:   "catalog",
+		Short: "Sync context definitions from a shared catalog",
+	}
+
+	catalogCmd.AddCommand(
+		newCatalogSyncCommand(mgr),
+		newCatalogTrustCommand(mgr),
+		newCatalogKeygenCommand(),
+		newCatalogSignCommand(),
+	)
+	return catalogCmd
+}
+
+func newCatalogSyncCommand(mgr *contexts.Manager) *cobra.Command {
+	var timeout time.Duration
+	var dryRun bool
+	var signatureSource string
+	var adopt bool
+
+	cmd := &cobra.Command{
+		Use:   "sync <url|path>",
+		Short: "Fetch a signed context catalog and reconcile local contexts (tokens are never modified)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source := args[0]
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+
+			data, err := catalog.Fetch(ctx, http.DefaultClient, source)
+			if err != nil {
+				return err
+			}
+
+			if signatureSource == "" {
+				signatureSource = catalog.SignatureSource(source)
+			}
+			signature, err := catalog.Fetch(ctx, http.DefaultClient, signatureSource)
+			if err != nil {
+				return fmt.Errorf("%w: fetch %s: %v", catalog.ErrUnsigned, signatureSource, err)
+			}
+
+			var report *catalog.Report
+			var signer string
+			err = mgr.Update(func(cfg *config.Config) error {
+				name, err := catalog.Verify(data, signature, cfg.TrustedCatalogKeys)
+				if err != nil {
+					return err
+				}
+				signer = name
+
+				// Only parse after verification so untrusted input is never interpreted.
+				cat, err := catalog.Parse(data)
+				if err != nil {
+					return err
+				}
+
+				report = catalog.Reconcile(cfg, cat, source, adopt)
+				if dryRun {
+					return errDryRun
+				}
+				return nil
+			})
+			if err != nil && !errors.Is(err, errDryRun) {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Catalog signature verified with key %q.\n", signer)
+			printCatalogReport(cmd.OutOrStdout(), report, dryRun)
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 30*time.Second, "Timeout for fetching the catalog")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would change without saving")
+	cmd.Flags().BoolVar(&adopt, "adopt", false, "Let the catalog manage locally created contexts of the same name; their stored tokens are kept")
+	cmd.Flags().StringVar(&signatureSource, "signature", "", "Location of the detached signature (defaults to the catalog location with .sig appended)")
+	return cmd
+}
+
+func newCatalogTrustCommand(mgr *contexts.Manager) *cobra.Command {
+	trustCmd := &cobra.Command{
+		Use:   "trust",
+		Short: "Manage public keys trusted to sign catalogs",
+	}
+
+	trustCmd.AddCommand(
+		&cobra.Command{
+			Use:   "add <name> <public-key>",
+			Short: "Trust an ed25519 public key (base64) for catalog signatures",
+			Args:  cobra.ExactArgs(2),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				name, key := strings.TrimSpace(args[0]), strings.TrimSpace(args[1])
+				if name == "" {
+					return errors.New("key name is required")
+				}
+				if _, err := catalog.ParsePublicKey(key); err != nil {
+					return err
+				}
+
+				err := mgr.Update(func(cfg *config.Config) error {
+					if cfg.TrustedCatalogKeys == nil {
+						cfg.TrustedCatalogKeys = make(map[string]string)
+					}
+					cfg.TrustedCatalogKeys[name] = key
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "Trusted catalog key %q.\n", name)
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "remove <name>",
+			Short: "Stop trusting a catalog key",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				name := args[0]
+				err := mgr.Update(func(cfg *config.Config) error {
+					if _, ok := cfg.TrustedCatalogKeys[name]; !ok {
+						return fmt.Errorf("no trusted catalog key named %q", name)
+					}
+					delete(cfg.TrustedCatalogKeys, name)
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "Removed catalog key %q.\n", name)
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "list",
+			Short: "List trusted catalog keys",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				keys, err := mgr.TrustedCatalogKeys()
+				if err != nil {
+					return err
+				}
+
+				out := cmd.OutOrStdout()
+				if len(keys) == 0 {
+					fmt.Fprintln(out, "No trusted catalog keys configured.")
+					return nil
+				}
+
+				names := make([]string, 0, len(keys))
+				for name := range keys {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				for _, name := range names {
+					fmt.Fprintf(out, "%s\t%s\n", name, keys[name])
+				}
+				return nil
+			},
+		},
+	)
+
+	return trustCmd
+}
+
+func newCatalogKeygenCommand() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Generate an ed25519 key pair for signing catalogs",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if output == "" {
+				return errors.New("--output is required")
+			}
+
+			pub, priv, err := catalog.GenerateKey()
+			if err != nil {
+				return err
+			}
+
+			f, err := os.OpenFile(output, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(f, priv); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Wrote private key to %s.\nPublic key: %s\n", output, pub)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "O", "", "File to write the private key to")
+	return cmd
+}
+
+func newCatalogSignCommand() *cobra.Command {
+	var keyFile string
+
+	cmd := &cobra.Command{
+		Use:   "sign <catalog-file>",
+		Short: "Write a detached signature (<catalog-file>.sig) for a catalog",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if keyFile == "" {
+				return errors.New("--key is required")
+			}
+
+			keyData, err := os.ReadFile(keyFile)
+			if err != nil {
+				return err
+			}
+			key, err := catalog.ParsePrivateKey(string(keyData))
+			if err != nil {
+				return err
+			}
+
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			if _, err := catalog.Parse(data); err != nil {
+				return err
+			}
+
+			sigPath := args[0] + ".sig"
+			if err := os.WriteFile(sigPath, catalog.Sign(data, key), 0o644); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Wrote signature to %s.\n", sigPath)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&keyFile, "key", "", "Private key file created by catalog keygen")
+	return cmd
+}
+
+func printCatalogReport(out io.Writer, report *catalog.Report, dryRun bool) {
+	prefix := ""
+	if dryRun {
+		prefix = "(dry run) "
+	}
+
+	for _, name := range report.Added {
+		fmt.Fprintf(out, "%sAdded context %q.\n", prefix, name)
+	}
+	for _, name := range report.Updated {
+		fmt.Fprintf(out, "%sUpdated context %q.\n", prefix, name)
+	}
+	for _, name := range report.Removed {
+		fmt.Fprintf(out, "%sContext %q is no longer in the catalog; flagged but kept.\n", prefix, name)
+	}
+	for _, name := range report.Conflicts {
+		fmt.Fprintf(out, "%sSkipped context %q: it is managed by another catalog.\n", prefix, name)
+	}
+	for _, name := range report.LocalConflicts {
+		fmt.Fprintf(out, "%sSkipped context %q: it was created locally; pass --adopt to let the catalog manage it.\n", prefix, name)
+	}
+	fmt.Fprintf(out, "%s%d added, %d updated, %d unchanged, %d removed, %d conflicts.\n",
+		prefix, len(report.Added), len(report.Updated), len(report.Unchanged), len(report.Removed), len(report.Conflicts)+len(report.LocalConflicts))
+}
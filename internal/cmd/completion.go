@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/brianmichel/nomad-context/internal/contexts"
+	"github.com/brianmichel/nomad-context/internal/nomadbin"
+)
+
+const nomadCompletionTimeout = 5 * time.Second
+
+func newCompletionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion bash|zsh|fish|powershell",
+		Short: "Generate the shell completion script",
+		Long: `Generate the shell completion script for nomad-context.
+
+Context names are completed for the ctx commands, and arguments of proxied
+nomad commands are completed by nomad itself using the active context.
+
+  bash:        source <(nomad-context completion bash)
+  zsh:         nomad-context completion zsh > "${fpath[1]}/_nomad-context"
+  fish:        nomad-context completion fish | source
+  PowerShell:  nomad-context completion powershell | Out-String | Invoke-Expression`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			out := cmd.OutOrStdout()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(out, true)
+			case "zsh":
+				return root.GenZshCompletion(out)
+			case "fish":
+				return root.GenFishCompletion(out, true)
+			case "powershell":
+				return root.GenPowerShellCompletionWithDesc(out)
+			default:
+				return fmt.Errorf("unsupported shell %q (want bash, zsh, fish or powershell)", args[0])
+			}
+		},
+	}
+}
+
+// A negative maxArgs completes any number of distinct names.
+func completeContextNames(mgr *contexts.Manager, maxArgs int) cobra.CompletionFunc {
+	return func(_ *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if maxArgs >= 0 && len(args) >= maxArgs {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		list, _, err := mgr.ResolveAll()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		completions := make([]cobra.Completion, 0, len(list))
+		for _, r := range list {
+			if containsArg(args, r.Context.Name) {
+				continue
+			}
+			completions = append(completions, cobra.CompletionWithDesc(r.Context.Name, r.Context.Address))
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func containsArg(args []string, want string) bool {
+	for _, arg := range args {
+		if arg == want {
+			return true
+		}
+	}
+	return false
+}
+
+// nomad implements the COMP_LINE protocol used by `complete -C nomad nomad`.
+func completeNomadArgs(mgr *contexts.Manager, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	env := os.Environ()
+	binary, _ := nomadbin.Resolve("", "")
+
+	if sel, err := activeSelection(mgr); err == nil {
+		if pinned, err := nomadbin.Resolve(sel.Context.NomadPath, sel.Context.NomadVersion); err == nil {
+			binary = pinned
+		}
+		if contextEnv, err := contextEnviron(mgr, sel); err == nil {
+			env = contextEnv
+		}
+	}
+
+	line := strings.Join(append(append([]string{"nomad"}, args...), toComplete), " ")
+	env = append(env, "COMP_LINE="+line, "COMP_POINT="+strconv.Itoa(len(line)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), nomadCompletionTimeout)
+	defer cancel()
+
+	command := exec.CommandContext(ctx, binary) // #nosec G204 -- binary is the configured nomad executable.
+	command.Env = env
+	output, err := command.Output()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	var completions []cobra.Completion
+	for _, candidate := range strings.Split(string(output), "\n") {
+		if candidate = strings.TrimSpace(candidate); candidate != "" {
+			completions = append(completions, candidate)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeOutputModes(_ *cobra.Command, _ []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return []cobra.Completion{outputJSON, outputYAML, outputName, outputWide}, cobra.ShellCompDirectiveNoFileComp
+}
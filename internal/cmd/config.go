@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/skew"
+)
+
+func newConfigCommand() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect and migrate the nomad-context configuration file",
+	}
+
+	configCmd.AddCommand(
+		newConfigPathCommand(),
+		newConfigConvertCommand(),
+		newConfigVersionCheckCommand(),
+	)
+
+	return configCmd
+}
+
+func newConfigPathCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "path",
+		Short: "Print the path of the active configuration file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			path, err := config.Path()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), path)
+			return nil
+		},
+	}
+}
+
+func newConfigConvertCommand() *cobra.Command {
+	var to string
+
+	cmd := &cobra.Command{
+		Use:   "convert",
+		Short: "Convert the configuration file to another format (json, yaml or toml)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if to == "" {
+				return errors.New("--to is required")
+			}
+
+			format, err := config.ParseFormat(to)
+			if err != nil {
+				return err
+			}
+
+			from, dest, err := config.Convert(format)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Converted %s to %s (original kept as %s.bak).\n", from, dest, from)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", "Target format: json, yaml or toml")
+	return cmd
+}
+
+func newConfigVersionCheckCommand() *cobra.Command {
+	var enable bool
+	var disable bool
+	var tolerance int
+	var ttl time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "version-check",
+		Short: "Show or change the CLI/server version skew check done before proxying",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if enable && disable {
+				return errors.New("--enable and --disable cannot be combined")
+			}
+			if tolerance < 0 {
+				return errors.New("--tolerance cannot be negative")
+			}
+
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			flags := cmd.Flags()
+			if flags.NFlag() > 0 {
+				if cfg.VersionCheck == nil {
+					cfg.VersionCheck = &config.VersionCheck{MinorTolerance: skew.DefaultMinorTolerance}
+				}
+				settings := cfg.VersionCheck
+				if enable || disable {
+					settings.Enabled = enable
+				}
+				if flags.Changed("tolerance") {
+					settings.MinorTolerance = tolerance
+				}
+				if flags.Changed("ttl") {
+					settings.TTL = ttl.String()
+				}
+				if err := config.Save(cfg); err != nil {
+					return err
+				}
+			}
+
+			settings := cfg.VersionCheck
+			if settings == nil {
+				settings = &config.VersionCheck{MinorTolerance: skew.DefaultMinorTolerance}
+			}
+			cacheTTL := settings.TTL
+			if cacheTTL == "" {
+				cacheTTL = skew.DefaultTTL.String()
+			}
+			state := "disabled"
+			if settings.Enabled {
+				state = "enabled"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Version check %s (minor tolerance %d, cache TTL %s).\n", state, settings.MinorTolerance, cacheTTL)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&enable, "enable", false, "Warn before proxying when the nomad CLI and cluster versions drift apart")
+	cmd.Flags().BoolVar(&disable, "disable", false, "Turn the version check off")
+	cmd.Flags().IntVar(&tolerance, "tolerance", skew.DefaultMinorTolerance, "Number of minor versions the CLI and cluster may differ by")
+	cmd.Flags().DurationVar(&ttl, "ttl", skew.DefaultTTL, "How long cluster versions are cached per context")
+	return cmd
+}
@@ -2,11 +2,14 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/jedib0t/go-pretty/v6/list"
 	"github.com/jedib0t/go-pretty/v6/table"
@@ -16,6 +19,9 @@ import (
 
 	"github.com/brianmichel/nomad-context/internal/config"
 	"github.com/brianmichel/nomad-context/internal/contexts"
+	"github.com/brianmichel/nomad-context/internal/nomadbin"
+	"github.com/brianmichel/nomad-context/internal/probe"
+	"github.com/brianmichel/nomad-context/internal/view"
 )
 
 const activeIndicator = "*"
@@ -31,41 +37,169 @@ func newCtxCommand(mgr *contexts.Manager) *cobra.Command {
 		newCtxSetCommand(mgr),
 		newCtxUseCommand(mgr),
 		newCtxDeleteCommand(mgr),
+		newCtxRenameCommand(mgr),
+		newCtxCopyCommand(mgr),
 		newCtxShowCommand(mgr),
+		newCtxEditCommand(mgr),
+		newCtxExportCommand(mgr),
+		newCtxImportCommand(mgr),
+		newCtxDoctorCommand(mgr),
+		newCtxDiscoverCommand(mgr),
+		newCtxEnvCommand(mgr),
+		newCtxExecCommand(mgr),
+		newCtxHistoryCommand(mgr),
 	)
 
 	return ctxCmd
 }
 
 func newCtxListCommand(mgr *contexts.Manager) *cobra.Command {
-	return &cobra.Command{
+	var check bool
+	var parallel int
+	var timeout time.Duration
+	var output string
+	var tmpl string
+	var selector string
+
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all stored contexts",
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			contextsList, current, err := mgr.List()
+			mode, err := parseOutputMode(output)
+			if err != nil {
+				return err
+			}
+
+			contextsList, current, err := selectContexts(mgr, selector)
 			if err != nil {
 				return err
 			}
 
 			out := cmd.OutOrStdout()
-			if len(contextsList) == 0 {
-				fmt.Fprintln(out, "No contexts configured.")
+			if mode == outputName {
+				for _, r := range contextsList {
+					fmt.Fprintln(out, r.Context.Name)
+				}
+				return nil
+			}
+			if len(contextsList) == 0 && tmpl == "" && (mode == outputDefault || mode == outputWide) {
+				if selector != "" {
+					fmt.Fprintf(out, "No contexts match %s.\n", selector)
+				} else {
+					fmt.Fprintln(out, "No contexts configured.")
+				}
 				return nil
 			}
 
-			renderContextTable(out, contextsList, current)
-			return nil
+			var results map[string]probe.Result
+			if check {
+				results, err = probeContexts(cmd.Context(), mgr, contextsList, probe.Options{Parallel: parallel, Timeout: timeout})
+				if err != nil {
+					return err
+				}
+			}
+
+			if mode == outputDefault && tmpl == "" {
+				renderContextTable(out, contextsList, current, results, nil)
+				return nil
+			}
+
+			views, err := contextViews(mgr, contextsList, current, results)
+			if err != nil {
+				return err
+			}
+			if tmpl != "" {
+				return view.Execute(out, tmpl, views)
+			}
+			if mode == outputWide {
+				renderContextTable(out, contextsList, current, results, views)
+				return nil
+			}
+			return writeStructured(out, views, mode)
 		},
 	}
+
+	cmd.Flags().BoolVar(&check, "check", false, "Probe every context and show reachability, version, leader, region and token validity")
+	cmd.Flags().IntVar(&parallel, "parallel", 8, "Maximum number of contexts probed at once with --check")
+	cmd.Flags().DurationVar(&timeout, "timeout", 3*time.Second, "Timeout for probing each context with --check")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format: json, yaml, name or wide (default table)")
+	_ = cmd.RegisterFlagCompletionFunc("output", completeOutputModes)
+	cmd.Flags().StringVarP(&tmpl, "template", "t", "", "Format the list of contexts with a Go template")
+	cmd.MarkFlagsMutuallyExclusive("output", "template")
+	addSelectorFlag(cmd, &selector)
+	return cmd
+}
+
+func contextViews(mgr *contexts.Manager, list []*contexts.Resolved, current string, results map[string]probe.Result) ([]view.Context, error) {
+	views := make([]view.Context, 0, len(list))
+	for _, r := range list {
+		var tokenFrom string
+		if r.Err == nil {
+			_, from, err := mgr.EffectiveToken(r.Context.Name)
+			if err != nil && !errors.Is(err, contexts.ErrTokenNotFound) {
+				return nil, err
+			}
+			tokenFrom = from
+		}
+		v := view.FromResolved(r, r.Context.Name == current, tokenFrom)
+		if results != nil {
+			v = v.WithCheck(results[r.Context.Name])
+		}
+		views = append(views, v)
+	}
+	return views, nil
+}
+
+func probeContexts(ctx context.Context, mgr *contexts.Manager, list []*contexts.Resolved, opts probe.Options) (map[string]probe.Result, error) {
+	targets := make([]probe.Target, 0, len(list))
+	for _, r := range list {
+		if r.Err != nil {
+			continue
+		}
+		sel, err := mgr.Named(r.Context.Name)
+		if err != nil {
+			return nil, err
+		}
+		api, err := apiOptions(mgr, sel)
+		if err != nil {
+			return nil, fmt.Errorf("context %q: %w", r.Context.Name, err)
+		}
+		targets = append(targets, probe.Target{Name: r.Context.Name, Address: r.Context.Address, API: api})
+	}
+
+	results := make(map[string]probe.Result, len(targets))
+	for _, result := range probe.All(ctx, targets, opts) {
+		results[result.Name] = result
+	}
+	return results, nil
 }
 
-func renderContextTable(out io.Writer, contexts []*config.Context, current string) {
+func renderContextTable(out io.Writer, resolved []*contexts.Resolved, current string, results map[string]probe.Result, views []view.Context) {
 	tw := table.NewWriter()
 	tw.SetOutputMirror(out)
 	tw.SetStyle(table.StyleRounded)
-	tw.AppendHeader(table.Row{"CURRENT", "NAME", "ADDRESS"})
 
 	useColor := shouldUseColor(out)
+	showTags := false
+	for _, r := range resolved {
+		if len(r.Context.Tags) > 0 {
+			showTags = true
+			break
+		}
+	}
+
+	header := table.Row{"CURRENT", "NAME", "ADDRESS"}
+	if showTags {
+		header = append(header, "TAGS")
+	}
+	if views != nil {
+		header = append(header, "NAMESPACE", "REGION", "PARENT", "TOKEN")
+	}
+	if results != nil {
+		header = append(header, "REACHABLE", "LATENCY", "VERSION", "LEADER", "LIVE REGION", "TOKEN OK")
+	}
+	tw.AppendHeader(header)
+
 	if useColor {
 		tw.SetRowPainter(func(row table.Row) text.Colors {
 			if len(row) == 0 {
@@ -78,32 +212,175 @@ func renderContextTable(out io.Writer, contexts []*config.Context, current strin
 		})
 	}
 
-	for _, ctx := range contexts {
+	for i, r := range resolved {
+		ctx := r.Context
 		currentIndicator := ""
 		if ctx.Name == current {
 			currentIndicator = activeIndicator
 		}
 
-		tw.AppendRow(table.Row{currentIndicator, ctx.Name, ctx.Address})
+		address := ctx.Address
+		if r.Err != nil {
+			address = fmt.Sprintf("error: %v", r.Err)
+		}
+		row := table.Row{currentIndicator, ctx.Name, address}
+		if showTags {
+			row = append(row, valueOrDash(contexts.FormatTags(ctx.Tags)))
+		}
+		if views != nil {
+			tokenStatus := formatTokenPresence(views[i].Token.Stored, useColor)
+			if from := views[i].Token.From; from != "" {
+				tokenStatus += fmt.Sprintf(" (from %s)", from)
+			}
+			row = append(row, valueOrDash(ctx.Namespace), valueOrDash(ctx.Region), valueOrDash(ctx.Parent), tokenStatus)
+		}
+		if results != nil {
+			if r.Err != nil {
+				row = append(row, "-", "-", "-", "-", "-", "-")
+			} else {
+				row = append(row, probeColumns(results[ctx.Name])...)
+			}
+		}
+		tw.AppendRow(row)
 	}
 
 	tw.Render()
 }
 
-func renderContextDetails(out io.Writer, ctx *config.Context, hasToken bool) {
+func probeColumns(result probe.Result) table.Row {
+	if !result.Reachable {
+		return table.Row{"no", "-", "-", "-", "-", "-"}
+	}
+
+	return table.Row{
+		"yes",
+		result.Latency.Round(time.Millisecond).String(),
+		valueOrDash(result.Version),
+		valueOrDash(result.Leader),
+		valueOrDash(result.Region),
+		string(result.Token),
+	}
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func renderContextDetails(out io.Writer, sel *contexts.Selection, tokenFrom string) {
+	ctx := sel.Context
+
 	listWriter := list.NewWriter()
 	listWriter.SetOutputMirror(out)
 	listWriter.SetStyle(list.StyleConnectedRounded)
 
 	listWriter.AppendItem(fmt.Sprintf("Context %q", ctx.Name))
 	listWriter.Indent()
-	listWriter.AppendItem(fmt.Sprintf("Address: %s", ctx.Address))
-	listWriter.AppendItem(fmt.Sprintf("Token stored: %s", formatTokenPresence(hasToken, shouldUseColor(out))))
+	if len(sel.Resolved.Chain) > 1 {
+		listWriter.AppendItem(fmt.Sprintf("Parents: %s", strings.Join(sel.Resolved.Chain[1:], " -> ")))
+	}
+	listWriter.AppendItem(fmt.Sprintf("Address: %s", annotateOrigin(sel, "address", ctx.Address, ctx.Address)))
+	if sel.Namespace != "" {
+		listWriter.AppendItem(fmt.Sprintf("Namespace: %s", annotateOrigin(sel, "namespace", sel.Namespace, ctx.Namespace)))
+	}
+	if sel.Region != "" {
+		listWriter.AppendItem(fmt.Sprintf("Region: %s", annotateOrigin(sel, "region", sel.Region, ctx.Region)))
+	}
+
+	if ctx.NomadPath != "" {
+		listWriter.AppendItem(fmt.Sprintf("Nomad binary: %s", annotateOrigin(sel, "nomad_path", ctx.NomadPath, ctx.NomadPath)))
+	}
+	if ctx.NomadVersion != "" {
+		listWriter.AppendItem(fmt.Sprintf("Nomad version: %s", annotateOrigin(sel, "nomad_version", ctx.NomadVersion, ctx.NomadVersion)))
+	}
+	if ctx.HTTPAuthUser != "" {
+		listWriter.AppendItem(fmt.Sprintf("HTTP auth user: %s", annotateOrigin(sel, "http_auth_user", ctx.HTTPAuthUser, ctx.HTTPAuthUser)))
+	}
+	if consul := ctx.Consul; consul != nil {
+		listWriter.AppendItem("Consul:")
+		listWriter.Indent()
+		appendSetting(listWriter, sel, "Address", "consul.address", consul.Address)
+		appendSetting(listWriter, sel, "Datacenter", "consul.datacenter", consul.Datacenter)
+		listWriter.UnIndent()
+	}
+	if vault := ctx.Vault; vault != nil {
+		listWriter.AppendItem("Vault:")
+		listWriter.Indent()
+		appendSetting(listWriter, sel, "Address", "vault.address", vault.Address)
+		appendSetting(listWriter, sel, "Namespace", "vault.namespace", vault.Namespace)
+		appendSetting(listWriter, sel, "Token source", "vault.token_source", vault.TokenSource)
+		listWriter.UnIndent()
+	}
+	if len(ctx.Env) > 0 || len(ctx.SecretEnv) > 0 {
+		listWriter.AppendItem("Environment:")
+		listWriter.Indent()
+		for _, key := range sortedEnvKeys(ctx) {
+			value, plain := ctx.Env[key]
+			if !plain {
+				value = "(secret)"
+			}
+			listWriter.AppendItem(annotateOrigin(sel, "env."+key, fmt.Sprintf("%s=%s", key, value), fmt.Sprintf("%s=%s", key, value)))
+		}
+		listWriter.UnIndent()
+	}
+
+	if len(ctx.Tags) > 0 {
+		listWriter.AppendItem("Tags:")
+		listWriter.Indent()
+		for _, tag := range strings.Split(contexts.FormatTags(ctx.Tags), ",") {
+			key, _, _ := strings.Cut(tag, "=")
+			listWriter.AppendItem(annotateOrigin(sel, "tags."+key, tag, tag))
+		}
+		listWriter.UnIndent()
+	}
+
+	tokenStatus := formatTokenPresence(tokenFrom != "", shouldUseColor(out))
+	if tokenFrom != "" && tokenFrom != ctx.Name {
+		tokenStatus += fmt.Sprintf(" (from %s)", tokenFrom)
+	}
+	listWriter.AppendItem(fmt.Sprintf("Token stored: %s", tokenStatus))
+	if ctx.Catalog != "" {
+		catalogNote := ctx.Catalog
+		if ctx.CatalogRemoved {
+			catalogNote += " (no longer listed)"
+		}
+		listWriter.AppendItem(fmt.Sprintf("Catalog: %s", catalogNote))
+	}
+	listWriter.AppendItem(fmt.Sprintf("Selected by: %s", sel.Describe()))
 	listWriter.UnIndentAll()
 
 	listWriter.Render()
 }
 
+func appendSetting(listWriter list.Writer, sel *contexts.Selection, label, field, value string) {
+	if value == "" {
+		return
+	}
+	listWriter.AppendItem(fmt.Sprintf("%s: %s", label, annotateOrigin(sel, field, value, value)))
+}
+
+func sortedEnvKeys(ctx *config.Context) []string {
+	keys := make([]string, 0, len(ctx.Env)+len(ctx.SecretEnv))
+	for key := range ctx.Env {
+		keys = append(keys, key)
+	}
+	keys = append(keys, ctx.SecretEnv...)
+	sort.Strings(keys)
+	return keys
+}
+
+func annotateOrigin(sel *contexts.Selection, field, shown, resolved string) string {
+	if shown != resolved {
+		return fmt.Sprintf("%s (from project file)", shown)
+	}
+	if sel.Resolved.Inherited(field) {
+		return fmt.Sprintf("%s (from %s)", shown, sel.Resolved.Source(field))
+	}
+	return shown
+}
+
 func formatTokenPresence(hasToken bool, useColor bool) string {
 	tokenStatus := "no"
 	if hasToken {
@@ -138,11 +415,26 @@ func newCtxSetCommand(mgr *contexts.Manager) *cobra.Command {
 	var addr string
 	var token string
 	var promptToken bool
+	var namespace string
+	var region string
+	var parent string
+	var envPairs []string
+	var secretEnvPairs []string
+	var unsetEnv []string
+	var tags []string
+	var unsetTags []string
+	var httpUser string
+	var httpPassword string
+	var promptHTTPPassword bool
+	var nomadPath string
+	var nomadVersion string
+	var companion companionFlags
 
 	cmd := &cobra.Command{
-		Use:   "set <name>",
-		Short: "Create or update a context",
-		Args:  cobra.ExactArgs(1),
+		Use:               "set <name>",
+		Short:             "Create or update a context",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeContextNames(mgr, 1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			name := args[0]
 
@@ -154,20 +446,89 @@ func newCtxSetCommand(mgr *contexts.Manager) *cobra.Command {
 				existing = nil
 			}
 
-			targetAddr := addr
-			if targetAddr == "" && existing != nil {
-				targetAddr = existing.Address
+			updated := &config.Context{Name: name}
+			if existing != nil {
+				*updated = *existing
+			}
+
+			flags := cmd.Flags()
+			if addr != "" {
+				updated.Address = addr
 			}
-			if targetAddr == "" {
-				return errors.New("address is required")
+			if flags.Changed("parent") {
+				updated.Parent = strings.TrimSpace(parent)
+			}
+			if updated.Address == "" && updated.Parent == "" {
+				return errors.New("address is required (or set --parent to inherit it)")
 			}
 
-			saveToken := false
-			tokenValue := strings.TrimSpace(token)
+			if flags.Changed("namespace") {
+				updated.Namespace = strings.TrimSpace(namespace)
+			}
+			if flags.Changed("region") {
+				updated.Region = strings.TrimSpace(region)
+			}
 
-			if tokenValue != "" {
-				saveToken = true
-			} else if promptToken {
+			if flags.Changed("http-user") {
+				updated.HTTPAuthUser = strings.TrimSpace(httpUser)
+			}
+
+			passwordValue := httpPassword
+			if passwordValue == "" && promptHTTPPassword {
+				input, err := promptForSecret(fmt.Sprintf("Enter HTTP basic auth password for %s: ", name))
+				if err != nil {
+					return err
+				}
+				passwordValue = input
+				if passwordValue == "" {
+					return errors.New("password cannot be empty")
+				}
+			}
+			if passwordValue != "" && updated.HTTPAuthUser == "" {
+				return errors.New("--http-user is required to store an HTTP basic auth password")
+			}
+
+			if flags.Changed("nomad-path") && flags.Changed("nomad-version") {
+				return errors.New("--nomad-path and --nomad-version cannot be combined")
+			}
+			if flags.Changed("nomad-path") {
+				updated.NomadPath = strings.TrimSpace(nomadPath)
+				updated.NomadVersion = ""
+			}
+			if flags.Changed("nomad-version") {
+				updated.NomadVersion = strings.TrimSpace(nomadVersion)
+				updated.NomadPath = ""
+				if updated.NomadVersion != "" {
+					if err := nomadbin.ValidateVersion(updated.NomadVersion); err != nil {
+						return err
+					}
+				}
+			}
+
+			if err := companion.apply(updated, flags); err != nil {
+				return err
+			}
+			consulToken, vaultToken, err := companion.secrets(name)
+			if err != nil {
+				return err
+			}
+
+			droppedSecrets, err := applyEnvFlags(updated, envPairs, unsetEnv)
+			if err != nil {
+				return err
+			}
+
+			secretValues, err := collectSecretEnv(name, secretEnvPairs)
+			if err != nil {
+				return err
+			}
+
+			if err := applyTagFlags(updated, tags, unsetTags); err != nil {
+				return err
+			}
+
+			tokenValue := strings.TrimSpace(token)
+			if tokenValue == "" && promptToken {
 				tokenInput, err := promptForSecret(fmt.Sprintf("Enter token for %s: ", name))
 				if err != nil {
 					return err
@@ -176,19 +537,42 @@ func newCtxSetCommand(mgr *contexts.Manager) *cobra.Command {
 				if tokenValue == "" {
 					return errors.New("token cannot be empty")
 				}
-				saveToken = true
 			}
 
-			tokenArg := ""
-			if saveToken {
-				tokenArg = tokenValue
+			if err := mgr.Put(updated, tokenValue); err != nil {
+				return err
+			}
+
+			if existing != nil && existing.HTTPAuthUser != "" && updated.HTTPAuthUser == "" {
+				if err := mgr.DeleteHTTPPassword(name); err != nil {
+					return err
+				}
+			} else if passwordValue != "" {
+				if err := mgr.SetHTTPPassword(name, passwordValue); err != nil {
+					return err
+				}
 			}
 
-			if err := mgr.Upsert(name, targetAddr, tokenArg); err != nil {
+			if err := companion.store(mgr, existing, updated, consulToken, vaultToken); err != nil {
 				return err
 			}
 
-			fmt.Fprintf(cmd.OutOrStdout(), "Saved context %q (%s).\n", name, targetAddr)
+			for _, key := range droppedSecrets {
+				if err := mgr.DeleteSecretEnv(name, key); err != nil {
+					return err
+				}
+			}
+			for _, secret := range secretValues {
+				if err := mgr.SetSecretEnv(name, secret.key, secret.value); err != nil {
+					return err
+				}
+			}
+
+			resolved, err := mgr.Resolved(name)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Saved context %q (%s).\n", name, resolved.Context.Address)
 			return nil
 		},
 	}
@@ -196,16 +580,148 @@ func newCtxSetCommand(mgr *contexts.Manager) *cobra.Command {
 	cmd.Flags().StringVar(&addr, "addr", "", "Nomad server address, e.g. https://nomad.service:4646")
 	cmd.Flags().StringVar(&token, "token", "", "Nomad ACL token to store securely")
 	cmd.Flags().BoolVar(&promptToken, "prompt-token", false, "Interactively prompt for the token (useful for rotation)")
+	cmd.Flags().StringVar(&namespace, "namespace", "", "Nomad namespace exported as NOMAD_NAMESPACE (empty to clear)")
+	cmd.Flags().StringVar(&region, "region", "", "Nomad region exported as NOMAD_REGION (empty to clear)")
+	cmd.Flags().StringVar(&parent, "parent", "", "Inherit unset fields and the token from another context (empty to clear)")
+	cmd.Flags().StringVar(&httpUser, "http-user", "", "HTTP basic auth username sent via NOMAD_HTTP_AUTH (empty to clear)")
+	cmd.Flags().StringVar(&httpPassword, "http-password", "", "HTTP basic auth password to store securely")
+	cmd.Flags().BoolVar(&promptHTTPPassword, "prompt-http-password", false, "Interactively prompt for the HTTP basic auth password")
+	cmd.Flags().StringVar(&nomadPath, "nomad-path", "", "nomad binary used for this context (empty to clear)")
+	cmd.Flags().StringVar(&nomadVersion, "nomad-version", "", "Installed nomad version used for this context, e.g. 1.4.7 (empty to clear)")
+	companion.register(cmd.Flags())
+	cmd.Flags().StringArrayVar(&envPairs, "env", nil, "Extra environment variable KEY=VALUE exported to nomad; may be repeated")
+	cmd.Flags().StringArrayVar(&secretEnvPairs, "secret-env", nil, "Secret environment variable KEY=VALUE (or KEY to prompt) kept in the keyring; may be repeated")
+	cmd.Flags().StringArrayVar(&unsetEnv, "unset-env", nil, "Remove an extra or secret environment variable; may be repeated")
+	cmd.Flags().StringArrayVar(&tags, "tag", nil, "Tag KEY=VALUE (or KEY) used to select contexts with -l; may be repeated")
+	cmd.Flags().StringArrayVar(&unsetTags, "unset-tag", nil, "Remove a tag; may be repeated")
 	return cmd
 }
 
+func applyTagFlags(ctx *config.Context, tags []string, unset []string) error {
+	merged := make(map[string]string, len(ctx.Tags)+len(tags))
+	for key, value := range ctx.Tags {
+		merged[key] = value
+	}
+
+	for _, tag := range tags {
+		key, value, err := contexts.ParseTag(tag)
+		if err != nil {
+			return err
+		}
+		merged[key] = value
+	}
+	for _, key := range unset {
+		delete(merged, strings.TrimSpace(key))
+	}
+
+	ctx.Tags = nil
+	if len(merged) > 0 {
+		ctx.Tags = merged
+	}
+	return nil
+}
+
+type secretEnvValue struct {
+	key   string
+	value string
+}
+
+// applyEnvFlags returns the secret variables whose stored values must be
+// removed.
+func applyEnvFlags(ctx *config.Context, pairs []string, unset []string) ([]string, error) {
+	env := make(map[string]string, len(ctx.Env)+len(pairs))
+	for key, value := range ctx.Env {
+		env[key] = value
+	}
+
+	var dropped []string
+	dropSecret := func(key string) {
+		kept := ctx.SecretEnv[:0:0]
+		for _, existing := range ctx.SecretEnv {
+			if existing == key {
+				dropped = append(dropped, key)
+				continue
+			}
+			kept = append(kept, existing)
+		}
+		ctx.SecretEnv = kept
+	}
+
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("--env expects KEY=VALUE, got %q", pair)
+		}
+		if err := contexts.ValidateEnvKey(key); err != nil {
+			return nil, err
+		}
+		env[key] = value
+		dropSecret(key)
+	}
+
+	for _, key := range unset {
+		delete(env, key)
+		dropSecret(key)
+	}
+
+	ctx.Env = nil
+	if len(env) > 0 {
+		ctx.Env = env
+	}
+	if len(ctx.SecretEnv) == 0 {
+		ctx.SecretEnv = nil
+	}
+	return dropped, nil
+}
+
+func collectSecretEnv(name string, pairs []string) ([]secretEnvValue, error) {
+	values := make([]secretEnvValue, 0, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if err := contexts.ValidateEnvKey(key); err != nil {
+			return nil, err
+		}
+		if !ok {
+			input, err := promptForSecret(fmt.Sprintf("Enter value of %s for %s: ", key, name))
+			if err != nil {
+				return nil, err
+			}
+			value = strings.TrimSpace(input)
+		}
+		if value == "" {
+			return nil, fmt.Errorf("secret value for %s cannot be empty", key)
+		}
+		values = append(values, secretEnvValue{key: key, value: value})
+	}
+	return values, nil
+}
+
 func newCtxUseCommand(mgr *contexts.Manager) *cobra.Command {
 	return &cobra.Command{
-		Use:   "use <name>",
-		Short: "Switch the active context",
-		Args:  cobra.ExactArgs(1),
+		Use:               "use [name|-]",
+		Short:             "Switch the active context (\"-\" returns to the previous one; pick interactively when no name is given)",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeContextNames(mgr, 1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			name := args[0]
+			var name string
+			switch {
+			case len(args) == 1 && args[0] == "-":
+				previous, err := mgr.UsePrevious()
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Now using context %q.\n", previous)
+				return nil
+			case len(args) == 1:
+				name = args[0]
+			default:
+				picked, err := pickContext(mgr)
+				if err != nil {
+					return err
+				}
+				name = picked
+			}
+
 			if err := mgr.Use(name); err != nil {
 				return err
 			}
@@ -217,9 +733,10 @@ func newCtxUseCommand(mgr *contexts.Manager) *cobra.Command {
 
 func newCtxDeleteCommand(mgr *contexts.Manager) *cobra.Command {
 	return &cobra.Command{
-		Use:   "delete <name>",
-		Short: "Remove a stored context",
-		Args:  cobra.ExactArgs(1),
+		Use:               "delete <name>",
+		Short:             "Remove a stored context",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeContextNames(mgr, 1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			name := args[0]
 			if err := mgr.Delete(name); err != nil {
@@ -231,36 +748,99 @@ func newCtxDeleteCommand(mgr *contexts.Manager) *cobra.Command {
 	}
 }
 
-func newCtxShowCommand(mgr *contexts.Manager) *cobra.Command {
+func newCtxRenameCommand(mgr *contexts.Manager) *cobra.Command {
 	return &cobra.Command{
-		Use:   "show [name]",
-		Short: "Display details for a context (defaults to current)",
-		Args:  cobra.MaximumNArgs(1),
+		Use:               "rename <old> <new>",
+		Short:             "Rename a context, moving its stored secrets",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeContextNames(mgr, 1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			target := ""
-			if len(args) > 0 {
-				target = args[0]
+			if err := mgr.Rename(args[0], args[1]); err != nil {
+				return err
 			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Renamed context %q to %q.\n", args[0], args[1])
+			return nil
+		},
+	}
+}
 
-			ctx, err := mgr.Resolve(target)
+func newCtxCopyCommand(mgr *contexts.Manager) *cobra.Command {
+	return &cobra.Command{
+		Use:               "copy <source> <destination>",
+		Short:             "Copy a context, including its stored secrets",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeContextNames(mgr, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := mgr.Copy(args[0], args[1]); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Copied context %q to %q.\n", args[0], args[1])
+			return nil
+		},
+	}
+}
+
+func newCtxShowCommand(mgr *contexts.Manager) *cobra.Command {
+	var output string
+	var tmpl string
+
+	cmd := &cobra.Command{
+		Use:               "show [name]",
+		Short:             "Display details for a context (defaults to current)",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeContextNames(mgr, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mode, err := parseOutputMode(output)
 			if err != nil {
 				return err
 			}
 
-			hasToken := true
-			if _, err := mgr.Token(ctx.Name); err != nil {
-				if errors.Is(err, contexts.ErrTokenNotFound) {
-					hasToken = false
-				} else {
+			sel, err := selectContext(mgr, args)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if mode == outputName {
+				fmt.Fprintln(out, sel.Context.Name)
+				return nil
+			}
+
+			_, tokenFrom, err := mgr.EffectiveToken(sel.Context.Name)
+			if err != nil && !errors.Is(err, contexts.ErrTokenNotFound) {
+				return err
+			}
+
+			if mode == outputJSON || mode == outputYAML || tmpl != "" {
+				_, current, err := mgr.List()
+				if err != nil {
 					return err
 				}
+				v := view.FromSelection(sel, sel.Context.Name == current, tokenFrom)
+				if tmpl != "" {
+					return view.Execute(out, tmpl, v)
+				}
+				return writeStructured(out, v, mode)
 			}
 
-			out := cmd.OutOrStdout()
-			renderContextDetails(out, ctx, hasToken)
+			renderContextDetails(out, sel, tokenFrom)
 			return nil
 		},
 	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format: json, yaml, name or wide (default details)")
+	_ = cmd.RegisterFlagCompletionFunc("output", completeOutputModes)
+	cmd.Flags().StringVarP(&tmpl, "template", "t", "", "Format the context with a Go template")
+	cmd.MarkFlagsMutuallyExclusive("output", "template")
+	return cmd
+}
+
+func selectContext(mgr *contexts.Manager, args []string) (*contexts.Selection, error) {
+	if len(args) > 0 {
+		return mgr.Named(args[0])
+	}
+
+	return activeSelection(mgr)
 }
 
 func promptForSecret(prompt string) (string, error) {
@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/spf13/cobra"
+
+	"github.com/brianmichel/nomad-context/internal/bundle"
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/contexts"
+)
+
+func newCtxExportCommand(mgr *contexts.Manager) *cobra.Command {
+	var formatName string
+	var output string
+	var includeSecrets bool
+	var recipients []string
+	var passphraseFile string
+	var selector string
+
+	cmd := &cobra.Command{
+		Use:               "export [names...]",
+		Short:             "Export context definitions to a portable bundle (tokens excluded unless --include-secrets)",
+		ValidArgsFunction: completeContextNames(mgr, -1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := config.ParseFormat(formatName)
+			if err != nil {
+				return err
+			}
+
+			if !includeSecrets && (len(recipients) > 0 || passphraseFile != "") {
+				return errors.New("--recipient and --passphrase-file require --include-secrets")
+			}
+
+			names := args
+			if selector != "" {
+				if len(args) > 0 {
+					return errors.New("context names cannot be combined with --selector")
+				}
+				matched, _, err := selectContexts(mgr, selector)
+				if err != nil {
+					return err
+				}
+				if len(matched) == 0 {
+					return fmt.Errorf("no contexts match %s", selector)
+				}
+				if matched = skipBroken(cmd.ErrOrStderr(), matched); len(matched) == 0 {
+					return errors.New("none of the selected contexts can be resolved")
+				}
+				for _, r := range matched {
+					names = append(names, r.Context.Name)
+				}
+			}
+
+			b, err := bundle.Export(mgr, names)
+			if err != nil {
+				return err
+			}
+
+			encode := func(w io.Writer) error {
+				return bundle.Encode(w, b, format)
+			}
+			if includeSecrets {
+				ageRecipients, err := exportRecipients(recipients, passphraseFile)
+				if err != nil {
+					return err
+				}
+				if err := bundle.IncludeSecrets(mgr, b); err != nil {
+					return err
+				}
+				encode = func(w io.Writer) error {
+					return bundle.EncodeSealed(w, b, format, ageRecipients...)
+				}
+			}
+
+			if output == "" || output == "-" {
+				return encode(cmd.OutOrStdout())
+			}
+
+			f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+			if err != nil {
+				return err
+			}
+			if err := encode(f); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Exported %d context(s) to %s.\n", len(b.Contexts), output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&formatName, "format", "json", "Bundle format: json or yaml")
+	cmd.Flags().StringVarP(&output, "output", "O", "", "Write the bundle to a file instead of stdout")
+	cmd.Flags().BoolVar(&includeSecrets, "include-secrets", false, "Include stored tokens and encrypt the bundle")
+	cmd.Flags().StringArrayVar(&recipients, "recipient", nil, "Encrypt to an age X25519 recipient (age1...); may be repeated")
+	cmd.Flags().StringVar(&passphraseFile, "passphrase-file", "", "Read the encryption passphrase from a file instead of prompting")
+	addSelectorFlag(cmd, &selector)
+	return cmd
+}
+
+func exportRecipients(recipients []string, passphraseFile string) ([]age.Recipient, error) {
+	if len(recipients) > 0 {
+		if passphraseFile != "" {
+			return nil, errors.New("use either --recipient or a passphrase, not both")
+		}
+		return bundle.ParseRecipients(recipients)
+	}
+
+	passphrase, err := readPassphrase(passphraseFile, true)
+	if err != nil {
+		return nil, err
+	}
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return []age.Recipient{recipient}, nil
+}
+
+func importIdentities(identityFile, passphraseFile string) ([]age.Identity, error) {
+	if identityFile != "" {
+		return bundle.LoadIdentities(identityFile)
+	}
+
+	passphrase, err := readPassphrase(passphraseFile, false)
+	if err != nil {
+		return nil, err
+	}
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return []age.Identity{identity}, nil
+}
+
+func readPassphrase(path string, confirm bool) (string, error) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		passphrase := strings.TrimSpace(string(data))
+		if passphrase == "" {
+			return "", errors.New("passphrase file is empty")
+		}
+		return passphrase, nil
+	}
+
+	passphrase, err := promptForSecret("Bundle passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", errors.New("passphrase cannot be empty")
+	}
+
+	if confirm {
+		again, err := promptForSecret("Confirm passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", errors.New("passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
+
+func newCtxImportCommand(mgr *contexts.Manager) *cobra.Command {
+	var onConflict string
+	var identityFile string
+	var passphraseFile string
+	var allowSensitive bool
+
+	cmd := &cobra.Command{
+		Use:   "import <file|->",
+		Short: "Import context definitions from a bundle",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			strategy, err := bundle.ParseStrategy(onConflict)
+			if err != nil {
+				return err
+			}
+
+			data, err := readInput(cmd.InOrStdin(), args[0])
+			if err != nil {
+				return err
+			}
+
+			var b *bundle.Bundle
+			if bundle.IsEncrypted(data) {
+				// The passphrase prompt reads stdin, which already held the bundle.
+				if args[0] == "-" && identityFile == "" && passphraseFile == "" {
+					return errors.New("an encrypted bundle read from stdin needs --passphrase-file or --identity")
+				}
+				identities, err := importIdentities(identityFile, passphraseFile)
+				if err != nil {
+					return err
+				}
+				b, err = bundle.DecodeSealed(data, identities...)
+				if err != nil {
+					return err
+				}
+			} else {
+				b, err = bundle.Decode(data)
+				if err != nil {
+					return err
+				}
+			}
+
+			report, err := bundle.Import(mgr, b, strategy, allowSensitive)
+			if errors.Is(err, bundle.ErrSensitiveEnv) {
+				return fmt.Errorf("%w; review them and pass --allow-sensitive-env to import anyway", err)
+			}
+			if report != nil {
+				printImportReport(cmd.OutOrStdout(), report)
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&onConflict, "on-conflict", string(bundle.StrategySkip), "How to handle existing contexts: skip, overwrite or rename")
+	cmd.Flags().StringVar(&identityFile, "identity", "", "age identity file used to decrypt bundles encrypted to a recipient")
+	cmd.Flags().StringVar(&passphraseFile, "passphrase-file", "", "Read the decryption passphrase from a file instead of prompting")
+	cmd.Flags().BoolVar(&allowSensitive, "allow-sensitive-env", false, "Accept env variables such as PATH, LD_PRELOAD or NOMAD_SKIP_VERIFY")
+	return cmd
+}
+
+func readInput(stdin io.Reader, path string) ([]byte, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, errors.New("input is empty")
+	}
+	return data, nil
+}
+
+func printImportReport(out io.Writer, report *bundle.Report) {
+	for _, name := range report.Added {
+		fmt.Fprintf(out, "Added context %q.\n", name)
+	}
+	for _, name := range report.Overwritten {
+		fmt.Fprintf(out, "Overwrote context %q.\n", name)
+	}
+	for _, pair := range report.SensitiveEnv {
+		fmt.Fprintf(out, "Warning: imported sensitive variable %s.\n", pair)
+	}
+	for _, name := range report.SecretsRemoved {
+		fmt.Fprintf(out, "Removed the stored secrets of %q because its address changed; set them again with ctx set.\n", name)
+	}
+
+	renamed := make([]string, 0, len(report.Renamed))
+	for name := range report.Renamed {
+		renamed = append(renamed, name)
+	}
+	sort.Strings(renamed)
+	for _, name := range renamed {
+		fmt.Fprintf(out, "Imported context %q as %q.\n", name, report.Renamed[name])
+	}
+
+	for _, name := range report.Skipped {
+		fmt.Fprintf(out, "Skipped existing context %q.\n", name)
+	}
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/contexts"
+)
+
+type companionFlags struct {
+	consulAddr        string
+	consulDatacenter  string
+	consulToken       string
+	promptConsulToken bool
+	vaultAddr         string
+	vaultNamespace    string
+	vaultTokenSource  string
+	vaultToken        string
+	promptVaultToken  bool
+}
+
+func (f *companionFlags) register(flags *pflag.FlagSet) {
+	flags.StringVar(&f.consulAddr, "consul-addr", "", "Consul address exported as CONSUL_HTTP_ADDR (empty to clear)")
+	flags.StringVar(&f.consulDatacenter, "consul-datacenter", "", "Consul datacenter exported as CONSUL_DATACENTER (empty to clear)")
+	flags.StringVar(&f.consulToken, "consul-token", "", "Consul ACL token to store securely, exported as CONSUL_HTTP_TOKEN")
+	flags.BoolVar(&f.promptConsulToken, "prompt-consul-token", false, "Interactively prompt for the Consul ACL token")
+	flags.StringVar(&f.vaultAddr, "vault-addr", "", "Vault address exported as VAULT_ADDR (empty to clear)")
+	flags.StringVar(&f.vaultNamespace, "vault-namespace", "", "Vault namespace exported as VAULT_NAMESPACE (empty to clear)")
+	flags.StringVar(&f.vaultTokenSource, "vault-token-source", "", "Where VAULT_TOKEN comes from: keyring, env:<VAR> or file:<path> (empty to clear)")
+	flags.StringVar(&f.vaultToken, "vault-token", "", "Vault token to store securely (sets --vault-token-source keyring)")
+	flags.BoolVar(&f.promptVaultToken, "prompt-vault-token", false, "Interactively prompt for the Vault token (sets --vault-token-source keyring)")
+}
+
+func (f *companionFlags) apply(ctx *config.Context, flags *pflag.FlagSet) error {
+	consul := config.ConsulSettings{}
+	if ctx.Consul != nil {
+		consul = *ctx.Consul
+	}
+	if flags.Changed("consul-addr") {
+		consul.Address = strings.TrimSpace(f.consulAddr)
+	}
+	if flags.Changed("consul-datacenter") {
+		consul.Datacenter = strings.TrimSpace(f.consulDatacenter)
+	}
+	ctx.Consul = nil
+	if consul != (config.ConsulSettings{}) {
+		ctx.Consul = &consul
+	}
+
+	vault := config.VaultSettings{}
+	if ctx.Vault != nil {
+		vault = *ctx.Vault
+	}
+	if flags.Changed("vault-addr") {
+		vault.Address = strings.TrimSpace(f.vaultAddr)
+	}
+	if flags.Changed("vault-namespace") {
+		vault.Namespace = strings.TrimSpace(f.vaultNamespace)
+	}
+	if flags.Changed("vault-token-source") {
+		vault.TokenSource = strings.TrimSpace(f.vaultTokenSource)
+	}
+	if f.vaultToken != "" || f.promptVaultToken {
+		if flags.Changed("vault-token-source") && vault.TokenSource != contexts.VaultTokenKeyring {
+			return fmt.Errorf("--vault-token requires --vault-token-source %s", contexts.VaultTokenKeyring)
+		}
+		vault.TokenSource = contexts.VaultTokenKeyring
+	}
+	if err := contexts.ValidateVaultTokenSource(vault.TokenSource); err != nil {
+		return err
+	}
+	ctx.Vault = nil
+	if vault != (config.VaultSettings{}) {
+		ctx.Vault = &vault
+	}
+
+	return nil
+}
+
+func (f *companionFlags) secrets(name string) (string, string, error) {
+	consulToken, err := flagOrPrompt(f.consulToken, f.promptConsulToken, fmt.Sprintf("Enter Consul token for %s: ", name))
+	if err != nil {
+		return "", "", err
+	}
+	vaultToken, err := flagOrPrompt(f.vaultToken, f.promptVaultToken, fmt.Sprintf("Enter Vault token for %s: ", name))
+	if err != nil {
+		return "", "", err
+	}
+	return consulToken, vaultToken, nil
+}
+
+// A stored Vault token is dropped once the context no longer reads it from
+// the keyring.
+func (f *companionFlags) store(mgr *contexts.Manager, previous, ctx *config.Context, consulToken, vaultToken string) error {
+	if consulToken != "" {
+		if err := mgr.SetConsulToken(ctx.Name, consulToken); err != nil {
+			return err
+		}
+	}
+
+	if vaultToken != "" {
+		return mgr.SetVaultToken(ctx.Name, vaultToken)
+	}
+	if usesKeyringVaultToken(previous) && !usesKeyringVaultToken(ctx) {
+		return mgr.DeleteVaultToken(ctx.Name)
+	}
+	return nil
+}
+
+func usesKeyringVaultToken(ctx *config.Context) bool {
+	return ctx != nil && ctx.Vault != nil && ctx.Vault.TokenSource == contexts.VaultTokenKeyring
+}
+
+func flagOrPrompt(value string, prompt bool, message string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value != "" || !prompt {
+		return value, nil
+	}
+
+	input, err := promptForSecret(message)
+	if err != nil {
+		return "", err
+	}
+	value = strings.TrimSpace(input)
+	if value == "" {
+		return "", fmt.Errorf("value cannot be empty")
+	}
+	return value, nil
+}
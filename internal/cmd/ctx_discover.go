@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/brianmichel/nomad-context/internal/contexts"
+	"github.com/brianmichel/nomad-context/internal/discover"
+	"github.com/brianmichel/nomad-context/internal/nomadapi"
+)
+
+func newCtxDiscoverCommand(mgr *contexts.Manager) *cobra.Command {
+	var yes bool
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:               "discover [name]",
+		Short:             "Discover namespaces and regions of a cluster and create derived contexts",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeContextNames(mgr, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sel, err := selectContext(mgr, args)
+			if err != nil {
+				return err
+			}
+			parent := sel.Context
+
+			api, err := apiOptions(mgr, sel)
+			if err != nil {
+				return err
+			}
+
+			all, _, err := mgr.List()
+			if err != nil {
+				return err
+			}
+			existing := make(map[string]struct{}, len(all))
+			for _, c := range all {
+				existing[c.Name] = struct{}{}
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+
+			client := nomadapi.New(parent.Address, api, &http.Client{Timeout: timeout})
+			candidates, err := discover.Discover(ctx, client, parent, existing)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			renderDiscoveryTable(out, candidates)
+
+			var pending []discover.Candidate
+			for _, candidate := range candidates {
+				if !candidate.Exists {
+					pending = append(pending, candidate)
+				}
+			}
+			if len(pending) == 0 {
+				fmt.Fprintln(out, "All discovered contexts already exist.")
+				return nil
+			}
+
+			if !yes {
+				if !term.IsTerminal(int(os.Stdin.Fd())) {
+					fmt.Fprintf(out, "Re-run with --yes to create %d context(s).\n", len(pending))
+					return nil
+				}
+				ok, err := promptConfirm(os.Stdin, fmt.Sprintf("Create %d context(s) derived from %q? [y/N] ", len(pending), parent.Name))
+				if err != nil {
+					return err
+				}
+				if !ok {
+					fmt.Fprintln(out, "No contexts created.")
+					return nil
+				}
+			}
+
+			for _, candidate := range pending {
+				if err := mgr.Put(candidate.Context, ""); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "Created context %q.\n", candidate.Context.Name)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Create all new derived contexts without prompting")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "Timeout for querying the cluster")
+	return cmd
+}
+
+func renderDiscoveryTable(out io.Writer, candidates []discover.Candidate) {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(out)
+	tw.SetStyle(table.StyleRounded)
+	tw.AppendHeader(table.Row{"NAME", "NAMESPACE", "REGION", "STATUS"})
+
+	for _, candidate := range candidates {
+		status := "new"
+		if candidate.Exists {
+			status = "exists"
+		}
+		ctx := candidate.Context
+		tw.AppendRow(table.Row{ctx.Name, ctx.Namespace, ctx.Region, status})
+	}
+
+	tw.Render()
+}
+
+func promptConfirm(in io.Reader, prompt string) (bool, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+
+	"github.com/brianmichel/nomad-context/internal/contexts"
+	"github.com/brianmichel/nomad-context/internal/doctor"
+)
+
+func newCtxDoctorCommand(mgr *contexts.Manager) *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:               "doctor [name]",
+		Short:             "Diagnose connectivity, TLS, cluster health and token validity for a context",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeContextNames(mgr, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sel, err := selectContext(mgr, args)
+			if err != nil {
+				return err
+			}
+			ctx := sel.Context
+
+			api, err := apiOptions(mgr, sel)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Diagnosing context %q (%s)\n", ctx.Name, ctx.Address)
+
+			results := doctor.Run(cmd.Context(), ctx.Address, api, doctor.Options{Timeout: timeout})
+			renderDoctorTable(out, results)
+
+			if doctor.Failed(results) {
+				return fmt.Errorf("context %q failed one or more checks", ctx.Name)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Second, "Timeout for each individual check")
+	return cmd
+}
+
+func renderDoctorTable(out io.Writer, results []doctor.Result) {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(out)
+	tw.SetStyle(table.StyleRounded)
+	tw.AppendHeader(table.Row{"CHECK", "STATUS", "DETAIL", "HINT"})
+	tw.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 3, WidthMax: 60},
+		{Number: 4, WidthMax: 60},
+	})
+
+	useColor := shouldUseColor(out)
+	for _, result := range results {
+		tw.AppendRow(table.Row{result.Check, formatDoctorStatus(result.Status, useColor), result.Detail, result.Hint})
+	}
+
+	tw.Render()
+}
+
+func formatDoctorStatus(status doctor.Status, useColor bool) string {
+	label := string(status)
+	if !useColor {
+		return label
+	}
+
+	switch status {
+	case doctor.StatusPass:
+		return text.Colors{text.FgHiGreen}.Sprint(label)
+	case doctor.StatusWarn:
+		return text.Colors{text.FgHiYellow}.Sprint(label)
+	case doctor.StatusFail:
+		return text.Colors{text.FgHiRed}.Sprint(label)
+	default:
+		return text.Colors{text.Faint}.Sprint(label)
+	}
+}
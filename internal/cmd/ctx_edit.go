@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/contexts"
+)
+
+const editErrorPrefix = "# ERROR: "
+
+func newCtxEditCommand(mgr *contexts.Manager) *cobra.Command {
+	return &cobra.Command{
+		Use:               "edit [name]",
+		Short:             "Edit a context definition in $EDITOR (defaults to current)",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeContextNames(mgr, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Without a name, edit the context commands would use here:
+			// NOMAD_CONTEXT, then the project file, then the current one.
+			sel, err := selectContext(mgr, args)
+			if err != nil {
+				return err
+			}
+			stored, err := mgr.Resolve(sel.Context.Name)
+			if err != nil {
+				return err
+			}
+			name := stored.Name
+
+			body, err := config.Marshal(stored, config.FormatYAML)
+			if err != nil {
+				return err
+			}
+
+			file, err := os.CreateTemp("", "nomad-context-*.yaml")
+			if err != nil {
+				return err
+			}
+			path := file.Name()
+			defer os.Remove(path)
+			if err := file.Close(); err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			content := append([]byte(editHeader(name)), body...)
+			var lastErr error
+			for {
+				if err := os.WriteFile(path, content, 0o600); err != nil {
+					return err
+				}
+				if err := runEditor(path); err != nil {
+					return err
+				}
+				edited, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+
+				if isBlankYAML(edited) {
+					fmt.Fprintln(out, "Edit cancelled.")
+					return nil
+				}
+				if bytes.Equal(edited, content) {
+					if lastErr != nil {
+						return fmt.Errorf("edit aborted: %w", lastErr)
+					}
+					fmt.Fprintln(out, "No changes made.")
+					return nil
+				}
+
+				var updated config.Context
+				err = config.UnmarshalStrict(edited, &updated, config.FormatYAML)
+				if err == nil {
+					err = mgr.Edit(name, &updated)
+				}
+				if err == nil {
+					fmt.Fprintf(out, "Saved context %q.\n", name)
+					return nil
+				}
+
+				lastErr = err
+				content = annotateEditErrors(edited, err)
+			}
+		},
+	}
+}
+
+func editHeader(name string) string {
+	return fmt.Sprintf(`# Editing context %q. Lines starting with # are ignored.
+# Secrets (tokens, passwords and secret_env values) are not shown and are kept.
+# Save and close the editor to apply the changes; empty the file to cancel.
+`, name)
+}
+
+func annotateEditErrors(content []byte, err error) []byte {
+	var b bytes.Buffer
+	for _, line := range strings.Split(err.Error(), "\n") {
+		b.WriteString(editErrorPrefix + line + "\n")
+	}
+	b.WriteString("# Fix the problems above and save again, or close without changes to abort.\n")
+
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		if strings.HasPrefix(line, editErrorPrefix) || strings.HasPrefix(line, "# Fix the problems above") {
+			continue
+		}
+		b.WriteString(line)
+	}
+	return b.Bytes()
+}
+
+func isBlankYAML(content []byte) bool {
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
+
+func runEditor(path string) error {
+	editor := editorCommand()
+	command := exec.Command(editor[0], append(editor[1:], path)...) // #nosec G204 -- the editor is chosen by the user.
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	if err := command.Run(); err != nil {
+		return fmt.Errorf("run editor %s: %w", editor[0], err)
+	}
+	return nil
+}
+
+// $VISUAL and $EDITOR may include arguments such as "code --wait".
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
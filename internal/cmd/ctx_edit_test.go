@@ -0,0 +1,49 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/contexts"
+)
+
+func TestCtxEditDefaultsToActiveContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	setupHome(t, &config.Config{
+		Current: "prod",
+		Contexts: map[string]*config.Context{
+			"prod":  {Name: "prod", Address: "https://prod"},
+			"stage": {Name: "stage", Address: "https://stage"},
+		},
+	})
+	t.Setenv(contexts.ContextEnv, "stage")
+
+	dir := t.TempDir()
+	capture := filepath.Join(dir, "edited.yaml")
+	editor := filepath.Join(dir, "editor")
+	if err := os.WriteFile(editor, []byte("#!/bin/sh\ncp \"$1\" \""+capture+"\"\n"), 0o755); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	t.Setenv("VISUAL", editor)
+
+	out, err := execute(t, "ctx", "edit")
+	if err != nil {
+		t.Fatalf("ctx edit error = %v", err)
+	}
+	if !strings.Contains(out, "No changes made.") {
+		t.Fatalf("ctx edit output = %q", out)
+	}
+	edited, err := os.ReadFile(capture)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.Contains(string(edited), `Editing context "stage"`) || !strings.Contains(string(edited), "https://stage") {
+		t.Fatalf("ctx edit opened the wrong context:\n%s", edited)
+	}
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/brianmichel/nomad-context/internal/contexts"
+)
+
+// newCtxExecCommand lives under ctx because a top-level exec would shadow the
+// proxied `nomad exec`.
+func newCtxExecCommand(mgr *contexts.Manager) *cobra.Command {
+	var name string
+
+	cmd := &cobra.Command{
+		Use:   "exec [--context name] [--] <command> [args...]",
+		Short: "Run any command with the active context's Nomad, Consul and Vault environment",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var sel *contexts.Selection
+			var err error
+			if name != "" {
+				sel, err = mgr.Named(name)
+			} else {
+				sel, err = activeSelection(mgr)
+			}
+			if err != nil {
+				return err
+			}
+
+			return runWithContext(mgr, sel, args[0], args[1:])
+		},
+	}
+
+	cmd.Flags().SetInterspersed(false)
+	cmd.Flags().StringVarP(&name, "context", "c", "", "Context to use instead of the active one")
+	_ = cmd.RegisterFlagCompletionFunc("context", completeContextNames(mgr, -1))
+	return cmd
+}
+
+func newCtxEnvCommand(mgr *contexts.Manager) *cobra.Command {
+	var shell string
+
+	cmd := &cobra.Command{
+		Use:               "env [name]",
+		Short:             "Print shell statements exporting a context's Nomad, Consul and Vault environment",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeContextNames(mgr, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sel, err := selectContext(mgr, args)
+			if err != nil {
+				return err
+			}
+
+			env, err := mgr.Environment(sel)
+			if err != nil {
+				return err
+			}
+
+			unset := contexts.TrackEnv(env, os.Getenv(contexts.EnvKeysVar))
+			return writeEnvExports(cmd.OutOrStdout(), env, unset, shell)
+		},
+	}
+
+	cmd.Flags().StringVar(&shell, "shell", "posix", "Output syntax: posix, fish or powershell")
+	return cmd
+}
+
+// Keys are written unquoted, so every key is checked before anything is
+// printed.
+func writeEnvExports(out io.Writer, env map[string]string, unset []string, shell string) error {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range append(keys, unset...) {
+		if err := contexts.ValidateEnvKey(key); err != nil {
+			return err
+		}
+	}
+
+	var format func(key, value string) string
+	var clear func(key string) string
+	switch shell {
+	case "posix", "bash", "zsh", "sh":
+		format = func(key, value string) string {
+			return fmt.Sprintf("export %s=%s", key, quoteSingle(value, `'\''`))
+		}
+		clear = func(key string) string {
+			return fmt.Sprintf("unset %s", key)
+		}
+	case "fish":
+		format = func(key, value string) string {
+			return fmt.Sprintf("set -gx %s %s", key, quoteSingle(strings.ReplaceAll(value, `\`, `\\`), `\'`))
+		}
+		clear = func(key string) string {
+			return fmt.Sprintf("set -e %s", key)
+		}
+	case "powershell", "pwsh":
+		format = func(key, value string) string {
+			return fmt.Sprintf("$Env:%s = %s", key, quoteSingle(value, `''`))
+		}
+		clear = func(key string) string {
+			return fmt.Sprintf("Remove-Item Env:%s -ErrorAction SilentlyContinue", key)
+		}
+	default:
+		return fmt.Errorf("unsupported shell %q (want posix, fish or powershell)", shell)
+	}
+
+	for _, key := range unset {
+		fmt.Fprintln(out, clear(key))
+	}
+	for _, key := range keys {
+		fmt.Fprintln(out, format(key, env[key]))
+	}
+	return nil
+}
+
+func quoteSingle(value, escapedQuote string) string {
+	return "'" + strings.ReplaceAll(value, "'", escapedQuote) + "'"
+}
@@ -0,0 +1,121 @@
+package cmd_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+
+	"github.com/brianmichel/nomad-context/internal/cmd"
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/contexts"
+)
+
+// setupHome points the config at a temporary directory holding cfg and runs
+// the test from an empty working directory so no project file applies.
+func setupHome(t *testing.T, cfg *config.Config) {
+	t.Helper()
+	t.Setenv("NOMAD_CONTEXT_HOME", t.TempDir())
+	t.Setenv(contexts.ContextEnv, "")
+	t.Chdir(t.TempDir())
+	keyring.MockInit()
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+}
+
+func execute(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	root := cmd.NewRootCmd()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs(args)
+	err := root.Execute()
+	return out.String(), err
+}
+
+func TestCtxEnvRejectsHostileKey(t *testing.T) {
+	setupHome(t, &config.Config{
+		Current: "prod",
+		Contexts: map[string]*config.Context{
+			"prod": {Name: "prod", Address: "https://prod", Env: map[string]string{"A$(touch${IFS}/tmp/pwned)": "x"}},
+		},
+	})
+
+	out, err := execute(t, "ctx", "env", "prod")
+	if err == nil {
+		t.Fatalf("ctx env expected an error for a hostile key, output %q", out)
+	}
+	if !strings.Contains(err.Error(), "invalid environment variable name") {
+		t.Fatalf("ctx env error = %v", err)
+	}
+	if strings.Contains(out, "touch") {
+		t.Fatalf("ctx env wrote the hostile key: %q", out)
+	}
+}
+
+func TestCtxEnvUnsetsManagedVariables(t *testing.T) {
+	setupHome(t, &config.Config{
+		Current: "prod",
+		Contexts: map[string]*config.Context{
+			"prod": {Name: "prod", Address: "https://prod", Namespace: "payments"},
+		},
+	})
+
+	out, err := execute(t, "ctx", "env", "prod")
+	if err != nil {
+		t.Fatalf("ctx env error = %v", err)
+	}
+	for _, want := range []string{"unset NOMAD_TOKEN\n", "unset NOMAD_REGION\n", "unset VAULT_TOKEN\n", "export NOMAD_ADDR='https://prod'\n"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("ctx env output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "unset NOMAD_NAMESPACE") {
+		t.Fatalf("ctx env unset a variable the context sets:\n%s", out)
+	}
+
+	out, err = execute(t, "ctx", "env", "prod", "--shell", "fish")
+	if err != nil {
+		t.Fatalf("ctx env --shell fish error = %v", err)
+	}
+	if !strings.Contains(out, "set -e CONSUL_HTTP_TOKEN\n") {
+		t.Fatalf("ctx env --shell fish output missing set -e:\n%s", out)
+	}
+}
+
+func TestCtxEnvClearsPreviousExtraVariables(t *testing.T) {
+	setupHome(t, &config.Config{
+		Current: "prod",
+		Contexts: map[string]*config.Context{
+			"prod":  {Name: "prod", Address: "https://prod", Env: map[string]string{"TEAM": "web"}},
+			"plain": {Name: "plain", Address: "https://plain"},
+		},
+	})
+	t.Setenv(contexts.EnvKeysVar, "NOMAD_SKIP_VERIFY,TEAM")
+
+	out, err := execute(t, "ctx", "env", "prod")
+	if err != nil {
+		t.Fatalf("ctx env error = %v", err)
+	}
+	for _, want := range []string{"unset NOMAD_SKIP_VERIFY\n", "export TEAM='web'\n", "export NOMAD_CONTEXT_ENV_KEYS='TEAM'\n"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("ctx env output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "unset TEAM") {
+		t.Fatalf("ctx env unset a variable the context sets:\n%s", out)
+	}
+
+	out, err = execute(t, "ctx", "env", "plain")
+	if err != nil {
+		t.Fatalf("ctx env error = %v", err)
+	}
+	for _, want := range []string{"unset TEAM\n", "unset NOMAD_CONTEXT_ENV_KEYS\n"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("ctx env output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+
+	"github.com/brianmichel/nomad-context/internal/contexts"
+)
+
+func newCtxHistoryCommand(mgr *contexts.Manager) *cobra.Command {
+	return &cobra.Command{
+		Use:   "history",
+		Short: "List recent context switches, most recent first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			history, err := mgr.History()
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if len(history) == 0 {
+				fmt.Fprintln(out, "No context switches recorded.")
+				return nil
+			}
+
+			tw := table.NewWriter()
+			tw.SetOutputMirror(out)
+			tw.SetStyle(table.StyleRounded)
+			tw.AppendHeader(table.Row{"SWITCHED AT", "CONTEXT"})
+			for _, entry := range history {
+				tw.AppendRow(table.Row{entry.SwitchedAt.Local().Format(time.DateTime), entry.Context})
+			}
+			tw.Render()
+			return nil
+		},
+	}
+}
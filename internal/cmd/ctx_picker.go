@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/brianmichel/nomad-context/internal/contexts"
+	"github.com/brianmichel/nomad-context/internal/picker"
+)
+
+// The picker is drawn on stderr so stdout stays clean.
+func pickContext(mgr *contexts.Manager) (string, error) {
+	list, current, err := mgr.ResolveAll()
+	if err != nil {
+		return "", err
+	}
+	if len(list) == 0 {
+		return "", errors.New("no contexts configured")
+	}
+
+	items := make([]picker.Item, 0, len(list))
+	selected := 0
+	for i, r := range list {
+		if r.Context.Name == current {
+			selected = i
+		}
+		description := r.Context.Address
+		if r.Err != nil {
+			description = fmt.Sprintf("error: %v", r.Err)
+		}
+		if len(r.Context.Tags) > 0 {
+			description += "  " + contexts.FormatTags(r.Context.Tags)
+		}
+		items = append(items, picker.Item{Name: r.Context.Name, Description: description})
+	}
+
+	idx, err := picker.Run(os.Stdin, os.Stderr, items, picker.Options{Prompt: "context> ", Selected: selected})
+	if errors.Is(err, picker.ErrNotInteractive) {
+		return "", errors.New("context name is required when not running in a terminal")
+	}
+	if err != nil {
+		return "", err
+	}
+	return list[idx].Context.Name, nil
+}
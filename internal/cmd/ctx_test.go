@@ -0,0 +1,46 @@
+package cmd_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+)
+
+func TestCtxListWideWithCheckHasDistinctHeaders(t *testing.T) {
+	setupHome(t, &config.Config{
+		Current: "prod",
+		Contexts: map[string]*config.Context{
+			"prod": {Name: "prod", Address: "http://127.0.0.1:1", Region: "eu"},
+		},
+	})
+
+	out, err := execute(t, "ctx", "list", "-o", "wide", "--check", "--timeout", "1s")
+	if err != nil {
+		t.Fatalf("ctx list error = %v", err)
+	}
+
+	var header []string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, "CURRENT") {
+			for _, cell := range strings.Split(line, "│") {
+				if cell = strings.TrimSpace(cell); cell != "" {
+					header = append(header, cell)
+				}
+			}
+			break
+		}
+	}
+	seen := map[string]bool{}
+	for _, cell := range header {
+		if seen[cell] {
+			t.Fatalf("header %q appears twice in %v", cell, header)
+		}
+		seen[cell] = true
+	}
+	for _, want := range []string{"REGION", "TOKEN", "LIVE REGION", "TOKEN OK"} {
+		if !seen[want] {
+			t.Fatalf("header %v missing %q", header, want)
+		}
+	}
+}
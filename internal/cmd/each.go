@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+
+	"github.com/brianmichel/nomad-context/internal/contexts"
+	"github.com/brianmichel/nomad-context/internal/fanout"
+	"github.com/brianmichel/nomad-context/internal/nomadbin"
+)
+
+func newEachCommand(mgr *contexts.Manager) *cobra.Command {
+	var selector string
+	var all bool
+	var parallel int
+
+	cmd := &cobra.Command{
+		Use:   "each (-l selector | --all) [--] <nomad args...>",
+		Short: "Run a nomad command against several contexts in parallel",
+		Long: `Run a nomad command against every context matching the selector, e.g.
+
+  nomad-context each -l env=prod -- job status api
+
+Each line of output is prefixed with the context it came from, and a summary
+of exit codes is printed on stderr once every command has finished. Commands
+run without stdin.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if selector == "" && !all {
+				return errors.New("select contexts with -l/--selector or pass --all")
+			}
+
+			list, _, err := selectContexts(mgr, selector)
+			if err != nil {
+				return err
+			}
+			if len(list) == 0 {
+				if selector == "" {
+					return errors.New("no contexts configured")
+				}
+				return fmt.Errorf("no contexts match %s", selector)
+			}
+			stderr := cmd.ErrOrStderr()
+			if list = skipBroken(stderr, list); len(list) == 0 {
+				return errors.New("none of the selected contexts can be resolved")
+			}
+
+			targets := make([]fanout.Target, 0, len(list))
+			for _, r := range list {
+				targets = append(targets, eachTarget(mgr, r.Context.Name, args))
+			}
+
+			results := fanout.Run(cmd.Context(), targets, fanout.Options{
+				Parallel: parallel,
+				Stdout:   cmd.OutOrStdout(),
+				Stderr:   stderr,
+			})
+
+			failed := renderEachSummary(stderr, results)
+			if failed > 0 {
+				return fmt.Errorf("%d of %d contexts failed", failed, len(results))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().SetInterspersed(false)
+	addSelectorFlag(cmd, &selector)
+	cmd.Flags().BoolVar(&all, "all", false, "Run against every context")
+	cmd.Flags().IntVarP(&parallel, "parallel", "p", 4, "Maximum number of commands running at once")
+	cmd.MarkFlagsMutuallyExclusive("selector", "all")
+	return cmd
+}
+
+// Preparation errors are kept on the target so the remaining contexts still
+// run.
+func eachTarget(mgr *contexts.Manager, name string, args []string) fanout.Target {
+	target := fanout.Target{Name: name, Args: args}
+
+	sel, err := mgr.Named(name)
+	if err != nil {
+		target.Err = err
+		return target
+	}
+	if target.Path, err = nomadbin.Resolve(sel.Context.NomadPath, sel.Context.NomadVersion); err != nil {
+		target.Err = err
+		return target
+	}
+	target.Env, target.Err = contextEnviron(mgr, sel)
+	return target
+}
+
+func renderEachSummary(out io.Writer, results []fanout.Result) int {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(out)
+	tw.SetStyle(table.StyleRounded)
+	tw.AppendHeader(table.Row{"CONTEXT", "EXIT", "DURATION", "ERROR"})
+
+	failed := 0
+	for _, result := range results {
+		if result.Failed() {
+			failed++
+		}
+		exit := "-"
+		if result.ExitCode >= 0 {
+			exit = strconv.Itoa(result.ExitCode)
+		}
+		duration := "-"
+		if result.Duration > 0 {
+			duration = result.Duration.Round(time.Millisecond).String()
+		}
+		errText := ""
+		if result.Err != nil {
+			errText = result.Err.Error()
+		}
+		tw.AppendRow(table.Row{result.Name, exit, duration, valueOrDash(errText)})
+	}
+
+	tw.Render()
+	return failed
+}
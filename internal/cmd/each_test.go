@@ -0,0 +1,52 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/nomadbin"
+)
+
+func TestEachAllWithoutContexts(t *testing.T) {
+	setupHome(t, &config.Config{Contexts: map[string]*config.Context{}})
+
+	if _, err := execute(t, "each", "--all", "--", "status"); err == nil || err.Error() != "no contexts configured" {
+		t.Fatalf("each --all error = %v, want no contexts configured", err)
+	}
+}
+
+func TestEachRunsAgainstSelectedContexts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	setupHome(t, &config.Config{
+		Current: "prod",
+		Contexts: map[string]*config.Context{
+			"prod":    {Name: "prod", Address: "https://prod", Tags: map[string]string{"env": "prod"}},
+			"prod-eu": {Name: "prod-eu", Address: "https://prod-eu", Tags: map[string]string{"env": "prod"}},
+			"dev":     {Name: "dev", Address: "https://dev", Tags: map[string]string{"env": "dev"}},
+		},
+	})
+	binary := filepath.Join(t.TempDir(), "nomad")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\necho \"$NOMAD_ADDR $*\"\n"), 0o755); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	t.Setenv(nomadbin.PathEnv, binary)
+
+	out, err := execute(t, "each", "-l", "env=prod", "--", "job", "status")
+	if err != nil {
+		t.Fatalf("each error = %v", err)
+	}
+	for _, want := range []string{"prod    | https://prod job status\n", "prod-eu | https://prod-eu job status\n", "EXIT"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("each output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "https://dev") {
+		t.Fatalf("each ran against an unselected context:\n%s", out)
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+)
+
+// The empty mode is the human-readable default.
+const (
+	outputDefault = ""
+	outputJSON    = "json"
+	outputYAML    = "yaml"
+	outputName    = "name"
+	outputWide    = "wide"
+)
+
+func parseOutputMode(value string) (string, error) {
+	mode := strings.ToLower(strings.TrimSpace(value))
+	switch mode {
+	case outputDefault, outputJSON, outputYAML, outputName, outputWide:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unsupported output %q (want json, yaml, name or wide)", value)
+	}
+}
+
+func writeStructured(out io.Writer, v any, mode string) error {
+	format := config.FormatJSON
+	if mode == outputYAML {
+		format = config.FormatYAML
+	}
+	data, err := config.Marshal(v, format)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
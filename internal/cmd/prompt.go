@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/brianmichel/nomad-context/internal/contexts"
+	"github.com/brianmichel/nomad-context/internal/prompt"
+)
+
+func newPromptCommand(mgr *contexts.Manager) *cobra.Command {
+	var (
+		format         string
+		symbol         string
+		shell          string
+		initShell      string
+		noColor        bool
+		symbolColor    string
+		contextColor   string
+		namespaceColor string
+		regionColor    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prompt",
+		Short: "Print the active context as a shell prompt segment",
+		Long: `Print the active context as a shell prompt segment, e.g. "⎈ prod".
+
+The context is chosen like for proxied commands: NOMAD_CONTEXT, then a project
+file, then the current context. Neither the keyring nor the network is used, so
+the command is cheap enough to run for every prompt. Nothing is printed when no
+context is configured.
+
+The format may use {symbol}, {context}, {namespace}, {region} and {source}.
+Colors are names (red, green, yellow, blue, magenta, cyan, white, gray, black),
+256-color numbers or "none"; NO_COLOR disables them.
+
+Print a ready-made snippet with --init bash, zsh, fish or starship.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			out := cmd.OutOrStdout()
+			if initShell != "" {
+				snippet, err := prompt.Init(initShell)
+				if err != nil {
+					return err
+				}
+				fmt.Fprint(out, snippet)
+				return nil
+			}
+
+			switch shell {
+			case prompt.ShellPlain, prompt.ShellBash, prompt.ShellZsh, prompt.ShellFish:
+			default:
+				return fmt.Errorf("unsupported shell %q (want bash, zsh or fish)", shell)
+			}
+
+			opts := prompt.Options{Format: format, Symbol: symbol, Shell: shell}
+			if !noColor && os.Getenv("NO_COLOR") == "" {
+				opts.Colors = map[string]string{
+					"symbol":    symbolColor,
+					"context":   contextColor,
+					"namespace": namespaceColor,
+					"region":    regionColor,
+				}
+			}
+
+			sel, err := activeSelection(mgr)
+			if err != nil {
+				if errors.Is(err, contexts.ErrNoCurrent) {
+					return nil
+				}
+				return err
+			}
+
+			segment, err := prompt.Render(prompt.Segment{
+				Context:   sel.Context.Name,
+				Namespace: sel.Namespace,
+				Region:    sel.Region,
+				Source:    string(sel.Source),
+			}, opts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(out, segment)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", prompt.DefaultFormat, "Segment format")
+	cmd.Flags().StringVar(&symbol, "symbol", prompt.DefaultSymbol, "Symbol printed for {symbol}")
+	cmd.Flags().StringVar(&shell, "shell", "", "Wrap color codes for the prompt of bash, zsh or fish")
+	cmd.Flags().StringVar(&initShell, "init", "", "Print a prompt snippet for bash, zsh, fish or starship")
+	cmd.Flags().BoolVar(&noColor, "no-color", false, "Print the segment without colors")
+	cmd.Flags().StringVar(&symbolColor, "symbol-color", "blue", "Color of {symbol}")
+	cmd.Flags().StringVar(&contextColor, "context-color", "red", "Color of {context}")
+	cmd.Flags().StringVar(&namespaceColor, "namespace-color", "cyan", "Color of {namespace}")
+	cmd.Flags().StringVar(&regionColor, "region-color", "magenta", "Color of {region}")
+	cmd.MarkFlagsMutuallyExclusive("init", "format")
+	_ = cmd.RegisterFlagCompletionFunc("shell", cobra.FixedCompletions([]string{prompt.ShellBash, prompt.ShellZsh, prompt.ShellFish}, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("init", cobra.FixedCompletions(prompt.InitShells, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,10 +9,10 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/brianmichel/nomad-context/internal/contexts"
+	"github.com/brianmichel/nomad-context/internal/nomadapi"
+	"github.com/brianmichel/nomad-context/internal/nomadbin"
 )
 
-const nomadBinaryEnv = "NOMAD_CONTEXT_NOMAD_PATH"
-
 func NewRootCmd() *cobra.Command {
 	mgr := contexts.NewManager()
 
@@ -23,59 +22,106 @@ func NewRootCmd() *cobra.Command {
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		Args:          cobra.ArbitraryArgs,
+		// Flags belong to the proxied nomad command, so the root command
+		// leaves them alone and only recognizes its own help and version.
+		DisableFlagParsing: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return cmd.Help()
 			}
+			switch args[0] {
+			case "-h", "--help":
+				return cmd.Help()
+			case "--version":
+				fmt.Fprintln(cmd.OutOrStdout(), formatVersionOutput(cmd))
+				return nil
+			}
 			return runNomad(args, mgr)
 		},
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			return completeNomadArgs(mgr, args, toComplete)
+		},
 	}
 
-	root.SetVersionTemplate("{{printf \"%s version %s\" .Name .Version}}\n")
 	root.Version = Version
+	root.CompletionOptions.DisableDefaultCmd = true
 
 	root.AddCommand(newCtxCommand(mgr))
+	root.AddCommand(newConfigCommand())
+	root.AddCommand(newCatalogCommand(mgr))
+	root.AddCommand(newPromptCommand(mgr))
+	root.AddCommand(newEachCommand(mgr))
 	root.AddCommand(newVersionCommand())
+	root.AddCommand(newCompletionCommand())
 	return root
 }
 
 func runNomad(args []string, mgr *contexts.Manager) error {
-	ctx, err := mgr.Current()
+	sel, err := activeSelection(mgr)
 	if err != nil {
 		return err
 	}
 
-	token, err := mgr.Token(ctx.Name)
+	binary, err := nomadbin.Resolve(sel.Context.NomadPath, sel.Context.NomadVersion)
 	if err != nil {
-		if errors.Is(err, contexts.ErrTokenNotFound) {
-			token = ""
-		} else {
-			return err
-		}
+		return fmt.Errorf("context %q: %w", sel.Context.Name, err)
 	}
+	warnVersionSkew(os.Stderr, mgr, sel, binary)
 
-	binary := os.Getenv(nomadBinaryEnv)
-	if binary == "" {
-		binary = "nomad"
-	}
+	return runWithContext(mgr, sel, binary, args)
+}
 
-	env := removeEnvVar(os.Environ(), "NOMAD_TOKEN")
-	overrides := map[string]string{
-		"NOMAD_ADDR": ctx.Address,
+func activeSelection(mgr *contexts.Manager) (*contexts.Selection, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
 	}
-	if token != "" {
-		overrides["NOMAD_TOKEN"] = token
+	return mgr.Active(wd)
+}
+
+func runWithContext(mgr *contexts.Manager, sel *contexts.Selection, binary string, args []string) error {
+	env, err := contextEnviron(mgr, sel)
+	if err != nil {
+		return err
 	}
 
 	command := exec.Command(binary, args...) // #nosec G204 -- arguments are provided intentionally by the user.
 	command.Stdout = os.Stdout
 	command.Stderr = os.Stderr
 	command.Stdin = os.Stdin
-	command.Env = overrideEnv(env, overrides)
+	command.Env = env
 
 	return command.Run()
 }
 
+// Managed variables and the extra ones of the previously evaluated context
+// are dropped unless the selected context sets them.
+func contextEnviron(mgr *contexts.Manager, sel *contexts.Selection) ([]string, error) {
+	overrides, err := mgr.Environment(sel)
+	if err != nil {
+		return nil, err
+	}
+	env := os.Environ()
+	for _, key := range contexts.TrackEnv(overrides, os.Getenv(contexts.EnvKeysVar)) {
+		env = removeEnvVar(env, key)
+	}
+	return overrideEnv(env, overrides), nil
+}
+
+// apiOptions mirrors what a proxied nomad command would send for sel.
+func apiOptions(mgr *contexts.Manager, sel *contexts.Selection) (nomadapi.Options, error) {
+	environ, err := contextEnviron(mgr, sel)
+	if err != nil {
+		return nomadapi.Options{}, err
+	}
+	env := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		key, value := splitEnvPair(kv)
+		env[key] = value
+	}
+	return nomadapi.OptionsFromEnv(env)
+}
+
 func overrideEnv(base []string, overrides map[string]string) []string {
 	result := make([]string, 0, len(base)+len(overrides))
 	used := make(map[string]struct{})
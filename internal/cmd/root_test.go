@@ -0,0 +1,114 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/cmd"
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/nomadbin"
+)
+
+// fakeNomad installs a nomad script that records its arguments and
+// environment in the returned file and answers completion requests with a
+// fixed list of subcommands.
+func fakeNomad(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	dir := t.TempDir()
+	record := filepath.Join(dir, "record")
+	script := `#!/bin/sh
+if [ -n "$COMP_LINE" ]; then
+  printf 'status\nstop\n'
+  exit 0
+fi
+printf '%s\n' "$@" > "` + record + `"
+echo "NOMAD_ADDR=$NOMAD_ADDR" >> "` + record + `"
+`
+	binary := filepath.Join(dir, "nomad")
+	if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	t.Setenv(nomadbin.PathEnv, binary)
+	return record
+}
+
+func setupProxy(t *testing.T) string {
+	t.Helper()
+	setupHome(t, &config.Config{
+		Current: "prod",
+		Contexts: map[string]*config.Context{
+			"prod":  {Name: "prod", Address: "https://prod"},
+			"stage": {Name: "stage", Address: "https://stage"},
+		},
+	})
+	return fakeNomad(t)
+}
+
+func TestRootProxiesNomadFlags(t *testing.T) {
+	record := setupProxy(t)
+
+	if _, err := execute(t, "job", "status", "-verbose", "api"); err != nil {
+		t.Fatalf("execute() error = %v", err)
+	}
+	data, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if want := "job\nstatus\n-verbose\napi\nNOMAD_ADDR=https://prod\n"; string(data) != want {
+		t.Fatalf("nomad received %q, want %q", data, want)
+	}
+}
+
+func TestRootVersionAndHelp(t *testing.T) {
+	setupProxy(t)
+
+	out, err := execute(t, "--version")
+	if err != nil {
+		t.Fatalf("--version error = %v", err)
+	}
+	if !strings.Contains(out, cmd.Version) {
+		t.Fatalf("--version output = %q", out)
+	}
+
+	for _, flag := range []string{"--help", "-h"} {
+		out, err := execute(t, flag)
+		if err != nil {
+			t.Fatalf("%s error = %v", flag, err)
+		}
+		if !strings.Contains(out, "Usage:") || !strings.Contains(out, "ctx") {
+			t.Fatalf("%s output = %q", flag, out)
+		}
+	}
+}
+
+func TestRootCompletesContextNames(t *testing.T) {
+	setupProxy(t)
+
+	out, err := execute(t, "__complete", "ctx", "use", "")
+	if err != nil {
+		t.Fatalf("__complete error = %v", err)
+	}
+	for _, want := range []string{"prod", "stage"} {
+		if !strings.Contains(out, want+"\n") && !strings.Contains(out, want+"\t") {
+			t.Fatalf("__complete ctx use output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRootCompletesNomadSubcommands(t *testing.T) {
+	setupProxy(t)
+
+	out, err := execute(t, "__complete", "job", "")
+	if err != nil {
+		t.Fatalf("__complete error = %v", err)
+	}
+	if !strings.HasPrefix(out, "status\nstop\n") {
+		t.Fatalf("__complete job output = %q", out)
+	}
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/brianmichel/nomad-context/internal/contexts"
+)
+
+func addSelectorFlag(cmd *cobra.Command, selector *string) {
+	cmd.Flags().StringVarP(selector, "selector", "l", "", "Only include contexts whose tags match, e.g. env=prod,team=payments")
+}
+
+// Contexts that cannot be resolved are included with Err set.
+func selectContexts(mgr *contexts.Manager, selector string) ([]*contexts.Resolved, string, error) {
+	sel, err := contexts.ParseSelector(selector)
+	if err != nil {
+		return nil, "", err
+	}
+
+	list, current, err := mgr.ResolveAll()
+	if err != nil {
+		return nil, "", err
+	}
+	return sel.Filter(list), current, nil
+}
+
+func skipBroken(out io.Writer, list []*contexts.Resolved) []*contexts.Resolved {
+	usable := make([]*contexts.Resolved, 0, len(list))
+	for _, r := range list {
+		if r.Err != nil {
+			fmt.Fprintf(out, "warning: skipping context %q: %v\n", r.Context.Name, r.Err)
+			continue
+		}
+		usable = append(usable, r)
+	}
+	return usable
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/contexts"
+	"github.com/brianmichel/nomad-context/internal/skew"
+)
+
+const versionCheckTimeout = 2 * time.Second
+
+// warnVersionSkew never blocks the proxied command; failures to determine
+// either version are ignored.
+func warnVersionSkew(out io.Writer, mgr *contexts.Manager, sel *contexts.Selection, binary string) {
+	cfg, err := config.Load()
+	if err != nil {
+		return
+	}
+	checker, err := skew.FromConfig(cfg.VersionCheck)
+	if err != nil {
+		fmt.Fprintf(out, "warning: %v\n", err)
+		return
+	}
+	if checker == nil {
+		return
+	}
+	checker.HTTP = &http.Client{Timeout: versionCheckTimeout}
+
+	api, err := apiOptions(mgr, sel)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), versionCheckTimeout)
+	defer cancel()
+
+	result, err := checker.Check(ctx, skew.Target{
+		Context: sel.Context.Name,
+		Address: sel.Context.Address,
+		API:     api,
+		Binary:  binary,
+	})
+	if err != nil || !result.Skewed {
+		return
+	}
+
+	fmt.Fprintf(out, "warning: nomad CLI %s does not match cluster %q (%s); pin a matching binary with `nomad-context ctx set %s --nomad-version %s`\n",
+		result.CLI, sel.Context.Name, result.Server, sel.Context.Name, result.Server)
+}
@@ -1,25 +1,70 @@
 package config
 
 import (
-	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
 )
 
 const (
 	envHomeOverride = "NOMAD_CONTEXT_HOME"
-	configFileName  = "config.json"
+	configFileBase  = "config"
 )
 
 type Context struct {
-	Name    string `json:"name"`
-	Address string `json:"address"`
+	Name           string            `json:"name" yaml:"name" toml:"name"`
+	Parent         string            `json:"parent,omitempty" yaml:"parent,omitempty" toml:"parent,omitempty"`
+	Address        string            `json:"address,omitempty" yaml:"address,omitempty" toml:"address,omitempty"`
+	Namespace      string            `json:"namespace,omitempty" yaml:"namespace,omitempty" toml:"namespace,omitempty"`
+	Region         string            `json:"region,omitempty" yaml:"region,omitempty" toml:"region,omitempty"`
+	HTTPAuthUser   string            `json:"http_auth_user,omitempty" yaml:"http_auth_user,omitempty" toml:"http_auth_user,omitempty"`
+	Consul         *ConsulSettings   `json:"consul,omitempty" yaml:"consul,omitempty" toml:"consul,omitempty"`
+	Vault          *VaultSettings    `json:"vault,omitempty" yaml:"vault,omitempty" toml:"vault,omitempty"`
+	NomadPath      string            `json:"nomad_path,omitempty" yaml:"nomad_path,omitempty" toml:"nomad_path,omitempty"`
+	NomadVersion   string            `json:"nomad_version,omitempty" yaml:"nomad_version,omitempty" toml:"nomad_version,omitempty"`
+	Tags           map[string]string `json:"tags,omitempty" yaml:"tags,omitempty" toml:"tags,omitempty"`
+	Env            map[string]string `json:"env,omitempty" yaml:"env,omitempty" toml:"env,omitempty"`
+	SecretEnv      []string          `json:"secret_env,omitempty" yaml:"secret_env,omitempty" toml:"secret_env,omitempty"`
+	Catalog        string            `json:"catalog,omitempty" yaml:"catalog,omitempty" toml:"catalog,omitempty"`
+	CatalogRemoved bool              `json:"catalog_removed,omitempty" yaml:"catalog_removed,omitempty" toml:"catalog_removed,omitempty"`
+}
+
+type ConsulSettings struct {
+	Address    string `json:"address,omitempty" yaml:"address,omitempty" toml:"address,omitempty"`
+	Datacenter string `json:"datacenter,omitempty" yaml:"datacenter,omitempty" toml:"datacenter,omitempty"`
+}
+
+// TokenSource is "keyring", "env:<VAR>" or "file:<path>"; empty leaves
+// VAULT_TOKEN alone.
+type VaultSettings struct {
+	Address     string `json:"address,omitempty" yaml:"address,omitempty" toml:"address,omitempty"`
+	Namespace   string `json:"namespace,omitempty" yaml:"namespace,omitempty" toml:"namespace,omitempty"`
+	TokenSource string `json:"token_source,omitempty" yaml:"token_source,omitempty" toml:"token_source,omitempty"`
 }
 
 type Config struct {
-	Current  string              `json:"current_context"`
-	Contexts map[string]*Context `json:"contexts"`
+	Current            string              `json:"current_context" yaml:"current_context" toml:"current_context"`
+	Previous           string              `json:"previous_context,omitempty" yaml:"previous_context,omitempty" toml:"previous_context,omitempty"`
+	History            []HistoryEntry      `json:"history,omitempty" yaml:"history,omitempty" toml:"history,omitempty"`
+	Contexts           map[string]*Context `json:"contexts" yaml:"contexts" toml:"contexts"`
+	TrustedCatalogKeys map[string]string   `json:"trusted_catalog_keys,omitempty" yaml:"trusted_catalog_keys,omitempty" toml:"trusted_catalog_keys,omitempty"`
+	VersionCheck       *VersionCheck       `json:"version_check,omitempty" yaml:"version_check,omitempty" toml:"version_check,omitempty"`
+}
+
+type HistoryEntry struct {
+	Context    string    `json:"context" yaml:"context" toml:"context"`
+	SwitchedAt time.Time `json:"switched_at" yaml:"switched_at" toml:"switched_at"`
+}
+
+// TTL is a duration such as "1h" for which cluster versions are cached.
+type VersionCheck struct {
+	Enabled        bool   `json:"enabled" yaml:"enabled" toml:"enabled"`
+	MinorTolerance int    `json:"minor_tolerance" yaml:"minor_tolerance" toml:"minor_tolerance"`
+	TTL            string `json:"ttl,omitempty" yaml:"ttl,omitempty" toml:"ttl,omitempty"`
 }
 
 func Load() (*Config, error) {
@@ -27,6 +72,14 @@ func Load() (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	return LoadFile(path)
+}
+
+func LoadFile(path string) (*Config, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
 
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -37,21 +90,29 @@ func Load() (*Config, error) {
 	}
 
 	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return nil, err
+	if err := Unmarshal(data, &cfg, format); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
 	}
 	cfg.ensure()
 	return &cfg, nil
 }
 
 func Save(cfg *Config) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	return SaveFile(cfg, path)
+}
+
+func SaveFile(cfg *Config, path string) error {
 	if cfg == nil {
 		return errors.New("config is nil")
 	}
 
 	cfg.ensure()
 
-	path, err := Path()
+	format, err := FormatFromPath(path)
 	if err != nil {
 		return err
 	}
@@ -61,7 +122,7 @@ func Save(cfg *Config) error {
 		return err
 	}
 
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	data, err := Marshal(cfg, format)
 	if err != nil {
 		return err
 	}
@@ -69,12 +130,41 @@ func Save(cfg *Config) error {
 	return os.WriteFile(path, data, 0o600)
 }
 
+// JSON is used when no config file exists yet.
 func Path() (string, error) {
 	dir, err := Dir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(dir, configFileName), nil
+
+	var found []string
+	for _, format := range formats {
+		for _, ext := range format.extensions() {
+			candidate := filepath.Join(dir, configFileBase+"."+ext)
+			if _, err := os.Stat(candidate); err == nil {
+				found = append(found, candidate)
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return "", err
+			}
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return PathFor(FormatJSON)
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf("multiple config files found (%s); remove all but one", strings.Join(found, ", "))
+	}
+}
+
+func PathFor(format Format) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, configFileBase+"."+format.extensions()[0]), nil
 }
 
 func Dir() (string, error) {
@@ -90,6 +180,58 @@ func Dir() (string, error) {
 	return filepath.Join(configDir, "nomad-context"), nil
 }
 
+// The old file is kept with a .bak suffix.
+func Convert(to Format) (string, string, error) {
+	from, err := Path()
+	if err != nil {
+		return "", "", err
+	}
+
+	current, err := FormatFromPath(from)
+	if err != nil {
+		return "", "", err
+	}
+	if current == to {
+		return "", "", fmt.Errorf("config is already in %s format", to)
+	}
+
+	if _, err := os.Stat(from); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", "", errors.New("no config file to convert")
+		}
+		return "", "", err
+	}
+
+	cfg, err := LoadFile(from)
+	if err != nil {
+		return "", "", err
+	}
+
+	dest, err := PathFor(to)
+	if err != nil {
+		return "", "", err
+	}
+	if err := SaveFile(cfg, dest); err != nil {
+		return "", "", err
+	}
+
+	converted, err := LoadFile(dest)
+	if err == nil && !reflect.DeepEqual(cfg, converted) {
+		err = fmt.Errorf("converted config in %s does not match the original", dest)
+	}
+	if err != nil {
+		_ = os.Remove(dest)
+		return "", "", err
+	}
+
+	if err := os.Rename(from, from+".bak"); err != nil {
+		_ = os.Remove(dest)
+		return "", "", err
+	}
+
+	return from, dest, nil
+}
+
 func (c *Config) ensure() {
 	if c.Contexts == nil {
 		c.Contexts = make(map[string]*Context)
@@ -2,9 +2,11 @@ package config_test
 
 import (
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/brianmichel/nomad-context/internal/config"
 )
@@ -86,3 +88,151 @@ func setConfigHome(t *testing.T) string {
 	t.Setenv("NOMAD_CONTEXT_HOME", dir)
 	return dir
 }
+
+func TestSaveAndLoadAlternateFormats(t *testing.T) {
+	for _, format := range []config.Format{config.FormatYAML, config.FormatTOML} {
+		t.Run(string(format), func(t *testing.T) {
+			setConfigHome(t)
+
+			path, err := config.PathFor(format)
+			if err != nil {
+				t.Fatalf("PathFor() error = %v", err)
+			}
+			if err := os.WriteFile(path, nil, 0o600); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			original := &config.Config{
+				Current: "prod",
+				Contexts: map[string]*config.Context{
+					"prod": {Name: "prod", Address: "https://prod:4646"},
+				},
+			}
+			if err := config.Save(original); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			detected, err := config.Path()
+			if err != nil {
+				t.Fatalf("Path() error = %v", err)
+			}
+			if detected != path {
+				t.Fatalf("Path() = %q, want %q", detected, path)
+			}
+
+			reloaded, err := config.Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if !reflect.DeepEqual(original, reloaded) {
+				t.Fatalf("reloaded config mismatch, want %+v got %+v", original, reloaded)
+			}
+		})
+	}
+}
+
+func TestPathRejectsMultipleConfigFiles(t *testing.T) {
+	dir := setConfigHome(t)
+
+	for _, name := range []string{"config.json", "config.yaml"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	if _, err := config.Path(); err == nil {
+		t.Fatalf("expected Path() to fail with multiple config files")
+	}
+}
+
+func TestConvertKeepsData(t *testing.T) {
+	dir := setConfigHome(t)
+
+	original := &config.Config{
+		Current: "dev",
+		Contexts: map[string]*config.Context{
+			"dev":  {Name: "dev", Address: "https://dev:4646"},
+			"prod": {Name: "prod", Address: "https://prod:4646"},
+		},
+	}
+	if err := config.Save(original); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	from, to, err := config.Convert(config.FormatYAML)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if from != filepath.Join(dir, "config.json") || to != filepath.Join(dir, "config.yaml") {
+		t.Fatalf("Convert() = %q, %q", from, to)
+	}
+	if _, err := os.Stat(from + ".bak"); err != nil {
+		t.Fatalf("expected backup of original config: %v", err)
+	}
+
+	reloaded, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(original, reloaded) {
+		t.Fatalf("converted config mismatch, want %+v got %+v", original, reloaded)
+	}
+
+	if _, _, err := config.Convert(config.FormatYAML); err == nil {
+		t.Fatalf("expected converting to the current format to fail")
+	}
+}
+
+func TestConvertKeepsHistory(t *testing.T) {
+	setConfigHome(t)
+
+	original := &config.Config{
+		Current:  "prod",
+		Previous: "dev",
+		History: []config.HistoryEntry{
+			{Context: "prod", SwitchedAt: time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)},
+		},
+		Contexts: map[string]*config.Context{
+			"dev":  {Name: "dev", Address: "https://dev:4646"},
+			"prod": {Name: "prod", Address: "https://prod:4646"},
+		},
+	}
+	if err := config.Save(original); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	for _, format := range []config.Format{config.FormatTOML, config.FormatYAML, config.FormatJSON} {
+		if _, _, err := config.Convert(format); err != nil {
+			t.Fatalf("Convert(%s) error = %v", format, err)
+		}
+		reloaded, err := config.Load()
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if len(reloaded.History) != 1 || !reloaded.History[0].SwitchedAt.Equal(original.History[0].SwitchedAt) {
+			t.Fatalf("history lost converting to %s: %+v", format, reloaded.History)
+		}
+	}
+}
+
+func TestUnmarshalStrictRejectsUnknownFields(t *testing.T) {
+	inputs := map[config.Format]string{
+		config.FormatJSON: `{"name": "dev", "adress": "https://dev"}`,
+		config.FormatYAML: "name: dev\nadress: https://dev\n",
+		config.FormatTOML: "name = \"dev\"\nadress = \"https://dev\"\n",
+	}
+	for format, input := range inputs {
+		var ctx config.Context
+		if err := config.UnmarshalStrict([]byte(input), &ctx, format); err == nil {
+			t.Fatalf("UnmarshalStrict(%s) expected error for unknown field", format)
+		}
+	}
+
+	var ctx config.Context
+	if err := config.UnmarshalStrict([]byte("name: dev\naddress: https://dev\n"), &ctx, config.FormatYAML); err != nil {
+		t.Fatalf("UnmarshalStrict() error = %v", err)
+	}
+	if ctx.Address != "https://dev" {
+		t.Fatalf("unexpected context %+v", ctx)
+	}
+}
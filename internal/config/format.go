@@ -0,0 +1,120 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+)
+
+var formats = []Format{FormatJSON, FormatYAML, FormatTOML}
+
+func ParseFormat(value string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "json":
+		return FormatJSON, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "toml":
+		return FormatTOML, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q (want json, yaml or toml)", value)
+	}
+}
+
+func FormatFromPath(path string) (Format, error) {
+	return ParseFormat(strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+func (f Format) extensions() []string {
+	if f == FormatYAML {
+		return []string{"yaml", "yml"}
+	}
+	return []string{string(f)}
+}
+
+func Marshal(v any, f Format) ([]byte, error) {
+	switch f {
+	case FormatJSON:
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case FormatYAML:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case FormatTOML:
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(v); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", f)
+	}
+}
+
+func Unmarshal(data []byte, v any, f Format) error {
+	switch f {
+	case FormatJSON:
+		return json.Unmarshal(data, v)
+	case FormatYAML:
+		return yaml.Unmarshal(data, v)
+	case FormatTOML:
+		return toml.Unmarshal(data, v)
+	default:
+		return fmt.Errorf("unsupported format %q", f)
+	}
+}
+
+// UnmarshalStrict is like Unmarshal but rejects fields that v does not
+// define, so typos in hand-edited files are reported instead of dropped.
+func UnmarshalStrict(data []byte, v any, f Format) error {
+	switch f {
+	case FormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		return dec.Decode(v)
+	case FormatYAML:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		return nil
+	case FormatTOML:
+		meta, err := toml.Decode(string(data), v)
+		if err != nil {
+			return err
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("unknown field %q", undecoded[0].String())
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q", f)
+	}
+}
@@ -0,0 +1,112 @@
+package contexts
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/nomadbin"
+)
+
+func ValidateContext(ctx *config.Context) error {
+	var errs []error
+
+	if ctx.Name == "" {
+		errs = append(errs, errors.New("name is required"))
+	} else if err := ValidateName(ctx.Name); err != nil {
+		errs = append(errs, err)
+	}
+	if ctx.Address == "" && ctx.Parent == "" {
+		errs = append(errs, errors.New("address is required (or set parent to inherit it)"))
+	}
+	if ctx.Address != "" {
+		if u, err := url.Parse(ctx.Address); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("address %q must be an http or https URL", ctx.Address))
+		}
+	}
+	if ctx.Parent != "" && ctx.Parent == ctx.Name {
+		errs = append(errs, errors.New("a context cannot be its own parent"))
+	}
+
+	for _, key := range ctx.SecretEnv {
+		if err := ValidateEnvKey(key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for key := range ctx.Env {
+		if err := ValidateEnvKey(key); err != nil {
+			errs = append(errs, err)
+		}
+		if containsString(ctx.SecretEnv, key) {
+			errs = append(errs, fmt.Errorf("%s is listed in both env and secret_env", key))
+		}
+	}
+
+	for key, value := range ctx.Tags {
+		if err := ValidateTag(key, value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if ctx.Vault != nil {
+		if err := ValidateVaultTokenSource(ctx.Vault.TokenSource); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if ctx.NomadPath != "" && ctx.NomadVersion != "" {
+		errs = append(errs, errors.New("set either nomad_path or nomad_version, not both"))
+	}
+	if ctx.NomadVersion != "" {
+		if err := nomadbin.ValidateVersion(ctx.NomadVersion); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Secret environment variables can be dropped but not added, since their
+// values are not part of the definition.
+func (m *Manager) Edit(name string, ctx *config.Context) error {
+	if ctx.Name != name {
+		return fmt.Errorf("name cannot be changed from %q here; use ctx rename", name)
+	}
+	if err := ValidateContext(ctx); err != nil {
+		return err
+	}
+
+	previous, err := m.Resolve(name)
+	if err != nil {
+		return err
+	}
+	for _, key := range ctx.SecretEnv {
+		if !containsString(previous.SecretEnv, key) {
+			return fmt.Errorf("secret_env %s has no stored value; add it with ctx set --secret-env", key)
+		}
+	}
+
+	if err := m.Put(ctx, ""); err != nil {
+		return err
+	}
+
+	for _, key := range previous.SecretEnv {
+		if !containsString(ctx.SecretEnv, key) {
+			if err := m.DeleteSecretEnv(name, key); err != nil {
+				return err
+			}
+		}
+	}
+	if previous.HTTPAuthUser != "" && ctx.HTTPAuthUser == "" {
+		if err := m.DeleteHTTPPassword(name); err != nil {
+			return err
+		}
+	}
+	if vaultSettings(previous).TokenSource == VaultTokenKeyring && vaultSettings(ctx).TokenSource != VaultTokenKeyring {
+		if err := m.DeleteVaultToken(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
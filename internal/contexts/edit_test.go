@@ -0,0 +1,74 @@
+package contexts_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/contexts"
+)
+
+func TestValidateContextReportsEveryProblem(t *testing.T) {
+	err := contexts.ValidateContext(&config.Context{
+		Name:         "bad",
+		Address:      "nomad.internal:4646",
+		Env:          map[string]string{"A B": "1", "TOKEN": "x"},
+		SecretEnv:    []string{"TOKEN"},
+		Vault:        &config.VaultSettings{TokenSource: "vault"},
+		NomadPath:    "/opt/nomad",
+		NomadVersion: "1.4.7",
+	})
+	if err == nil {
+		t.Fatalf("expected validation errors")
+	}
+
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 problems, got %d: %v", len(lines), err)
+	}
+
+	if err := contexts.ValidateContext(&config.Context{Name: "ok", Address: "https://nomad:4646"}); err != nil {
+		t.Fatalf("ValidateContext() error = %v", err)
+	}
+}
+
+func TestManagerEdit(t *testing.T) {
+	mgr := newTestManager(t)
+
+	if err := mgr.Put(&config.Context{Name: "prod", Address: "https://prod", HTTPAuthUser: "ops"}, ""); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := mgr.SetHTTPPassword("prod", "hunter2"); err != nil {
+		t.Fatalf("SetHTTPPassword() error = %v", err)
+	}
+	if err := mgr.SetSecretEnv("prod", "API_KEY", "k"); err != nil {
+		t.Fatalf("SetSecretEnv() error = %v", err)
+	}
+
+	if err := mgr.Edit("prod", &config.Context{Name: "production", Address: "https://prod"}); err == nil {
+		t.Fatalf("expected renaming through Edit to fail")
+	}
+	if err := mgr.Edit("prod", &config.Context{Name: "prod", Address: "https://prod", SecretEnv: []string{"API_KEY", "NEW"}}); err == nil {
+		t.Fatalf("expected adding a secret env without a value to fail")
+	}
+
+	edited := &config.Context{Name: "prod", Address: "https://prod.internal", Region: "eu"}
+	if err := mgr.Edit("prod", edited); err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+
+	stored, err := mgr.Resolve("prod")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if stored.Address != "https://prod.internal" || stored.Region != "eu" || stored.HTTPAuthUser != "" {
+		t.Fatalf("unexpected stored context %+v", stored)
+	}
+	if _, err := mgr.HTTPPassword("prod"); !errors.Is(err, contexts.ErrSecretNotFound) {
+		t.Fatalf("expected HTTP password to be removed, got %v", err)
+	}
+	if _, err := mgr.SecretEnvValue("prod", "API_KEY"); !errors.Is(err, contexts.ErrSecretNotFound) {
+		t.Fatalf("expected dropped secret env to be removed, got %v", err)
+	}
+}
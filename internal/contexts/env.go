@@ -0,0 +1,217 @@
+package contexts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Managed variables a context does not set are cleared rather than inherited,
+// so a previous context's credentials never reach the selected cluster.
+var ManagedEnv = []string{
+	"NOMAD_ADDR",
+	"NOMAD_TOKEN",
+	"NOMAD_NAMESPACE",
+	"NOMAD_REGION",
+	"NOMAD_HTTP_AUTH",
+	"CONSUL_HTTP_ADDR",
+	"CONSUL_DATACENTER",
+	"CONSUL_HTTP_TOKEN",
+	"VAULT_ADDR",
+	"VAULT_NAMESPACE",
+	"VAULT_TOKEN",
+}
+
+const EnvKeysVar = "NOMAD_CONTEXT_ENV_KEYS"
+
+func TrackEnv(env map[string]string, previous string) []string {
+	managed := make(map[string]struct{}, len(ManagedEnv))
+	for _, key := range ManagedEnv {
+		managed[key] = struct{}{}
+	}
+
+	var extra []string
+	for key := range env {
+		if _, ok := managed[key]; !ok && key != EnvKeysVar {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+
+	unset := map[string]struct{}{}
+	for _, key := range ManagedEnv {
+		unset[key] = struct{}{}
+	}
+	for _, key := range strings.Split(previous, ",") {
+		if key = strings.TrimSpace(key); ValidateEnvKey(key) == nil {
+			unset[key] = struct{}{}
+		}
+	}
+	if len(extra) > 0 {
+		env[EnvKeysVar] = strings.Join(extra, ",")
+	} else {
+		unset[EnvKeysVar] = struct{}{}
+	}
+
+	keys := make([]string, 0, len(unset))
+	for key := range unset {
+		if _, ok := env[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Companion settings take precedence over extra variables of the same name.
+func (m *Manager) Environment(sel *Selection) (map[string]string, error) {
+	ctx := sel.Context
+	env := make(map[string]string, len(ctx.Env)+len(ctx.SecretEnv)+4)
+
+	for key, value := range ctx.Env {
+		env[key] = value
+	}
+
+	for _, key := range ctx.SecretEnv {
+		owner := sel.Resolved.SecretEnvOwners[key]
+		value, err := m.SecretEnvValue(owner, key)
+		if err != nil {
+			return nil, err
+		}
+		env[key] = value
+	}
+
+	env["NOMAD_ADDR"] = ctx.Address
+
+	token, _, err := m.EffectiveToken(ctx.Name)
+	if err != nil && !errors.Is(err, ErrTokenNotFound) {
+		return nil, err
+	}
+	if token != "" {
+		env["NOMAD_TOKEN"] = token
+	}
+	if sel.Namespace != "" {
+		env["NOMAD_NAMESPACE"] = sel.Namespace
+	}
+	if sel.Region != "" {
+		env["NOMAD_REGION"] = sel.Region
+	}
+
+	if ctx.HTTPAuthUser != "" {
+		auth := ctx.HTTPAuthUser
+		var password string
+		if owner := sel.Resolved.Source("http_auth_user"); sel.Resolved.ownsSecret("address", owner) {
+			password, err = m.HTTPPassword(owner)
+			if err != nil && !errors.Is(err, ErrSecretNotFound) {
+				return nil, err
+			}
+		}
+		if password != "" {
+			auth += ":" + password
+		}
+		env["NOMAD_HTTP_AUTH"] = auth
+	}
+
+	if err := m.companionEnvironment(sel, env); err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+func (m *Manager) companionEnvironment(sel *Selection, env map[string]string) error {
+	ctx := sel.Context
+
+	if consul := ctx.Consul; consul != nil {
+		if consul.Address != "" {
+			env["CONSUL_HTTP_ADDR"] = consul.Address
+		}
+		if consul.Datacenter != "" {
+			env["CONSUL_DATACENTER"] = consul.Datacenter
+		}
+	}
+
+	consulToken, _, err := m.nearestSecret(sel.Resolved.secretChain("consul.address"), consulTokenAccount)
+	if err != nil {
+		return err
+	}
+	if consulToken != "" {
+		env["CONSUL_HTTP_TOKEN"] = consulToken
+	}
+
+	vault := ctx.Vault
+	if vault == nil {
+		return nil
+	}
+	if vault.Address != "" {
+		env["VAULT_ADDR"] = vault.Address
+	}
+	if vault.Namespace != "" {
+		env["VAULT_NAMESPACE"] = vault.Namespace
+	}
+
+	owner := sel.Resolved.Source("vault.token_source")
+	if vault.TokenSource == VaultTokenKeyring && !sel.Resolved.ownsSecret("vault.address", owner) {
+		return nil
+	}
+	token, err := m.vaultToken(vault.TokenSource, owner)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		env["VAULT_TOKEN"] = token
+	}
+	return nil
+}
+
+func (m *Manager) vaultToken(source, owner string) (string, error) {
+	kind, arg, _ := strings.Cut(source, ":")
+	switch kind {
+	case "":
+		return "", nil
+	case VaultTokenKeyring:
+		return m.VaultToken(owner)
+	case VaultTokenEnv:
+		return os.Getenv(arg), nil
+	case VaultTokenFile:
+		data, err := os.ReadFile(expandHome(arg))
+		if err != nil {
+			return "", fmt.Errorf("read vault token: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	default:
+		return "", fmt.Errorf("unknown vault token source %q", source)
+	}
+}
+
+const (
+	VaultTokenKeyring = "keyring"
+	VaultTokenEnv     = "env"
+	VaultTokenFile    = "file"
+)
+
+func ValidateVaultTokenSource(source string) error {
+	kind, arg, hasArg := strings.Cut(source, ":")
+	switch {
+	case source == "":
+		return nil
+	case kind == VaultTokenKeyring && !hasArg:
+		return nil
+	case (kind == VaultTokenEnv || kind == VaultTokenFile) && arg != "":
+		return nil
+	default:
+		return fmt.Errorf("invalid vault token source %q (want keyring, env:<VAR> or file:<path>)", source)
+	}
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
@@ -0,0 +1,244 @@
+package contexts_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/contexts"
+)
+
+func TestManagerEnvironment(t *testing.T) {
+	mgr := newTestManager(t)
+
+	parent := &config.Context{
+		Name:    "prod",
+		Address: "https://prod",
+		Env: map[string]string{
+			"CONSUL_HTTP_ADDR": "https://consul.prod",
+			"NOMAD_ADDR":       "https://ignored",
+		},
+	}
+	if err := mgr.Put(parent, "prod-token"); err != nil {
+		t.Fatalf("Put(prod) error = %v", err)
+	}
+	if err := mgr.SetSecretEnv("prod", "VAULT_TOKEN", "vault-secret"); err != nil {
+		t.Fatalf("SetSecretEnv() error = %v", err)
+	}
+
+	child := &config.Context{
+		Name:      "prod/payments",
+		Parent:    "prod",
+		Namespace: "payments",
+		Env:       map[string]string{"CONSUL_HTTP_ADDR": "https://consul.payments"},
+	}
+	if err := mgr.Put(child, ""); err != nil {
+		t.Fatalf("Put(prod/payments) error = %v", err)
+	}
+
+	sel, err := mgr.Named("prod/payments")
+	if err != nil {
+		t.Fatalf("Named() error = %v", err)
+	}
+
+	env, err := mgr.Environment(sel)
+	if err != nil {
+		t.Fatalf("Environment() error = %v", err)
+	}
+
+	want := map[string]string{
+		"NOMAD_ADDR":       "https://prod",
+		"NOMAD_TOKEN":      "prod-token",
+		"NOMAD_NAMESPACE":  "payments",
+		"CONSUL_HTTP_ADDR": "https://consul.payments",
+		"VAULT_TOKEN":      "vault-secret",
+	}
+	for key, value := range want {
+		if env[key] != value {
+			t.Fatalf("env[%s] = %q, want %q", key, env[key], value)
+		}
+	}
+	if len(env) != len(want) {
+		t.Fatalf("unexpected environment: %+v", env)
+	}
+}
+
+func TestManagerDeleteRemovesSecretEnv(t *testing.T) {
+	mgr := newTestManager(t)
+
+	if err := mgr.Upsert("dev", "https://dev", ""); err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+	if err := mgr.SetSecretEnv("dev", "NOMAD_HTTP_AUTH", "user:pass"); err != nil {
+		t.Fatalf("SetSecretEnv() error = %v", err)
+	}
+
+	ctx, err := mgr.Resolve("dev")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if len(ctx.SecretEnv) != 1 || ctx.SecretEnv[0] != "NOMAD_HTTP_AUTH" {
+		t.Fatalf("expected secret env to be recorded in config, got %+v", ctx.SecretEnv)
+	}
+
+	if err := mgr.Delete("dev"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := mgr.SecretEnvValue("dev", "NOMAD_HTTP_AUTH"); !errors.Is(err, contexts.ErrSecretNotFound) {
+		t.Fatalf("SecretEnvValue() error = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestManagerEnvironmentHTTPAuth(t *testing.T) {
+	mgr := newTestManager(t)
+
+	if err := mgr.Put(&config.Context{Name: "edge", Address: "https://edge", HTTPAuthUser: "ops"}, ""); err != nil {
+		t.Fatalf("Put(edge) error = %v", err)
+	}
+	if err := mgr.Put(&config.Context{Name: "edge/batch", Parent: "edge"}, ""); err != nil {
+		t.Fatalf("Put(edge/batch) error = %v", err)
+	}
+
+	sel, err := mgr.Named("edge/batch")
+	if err != nil {
+		t.Fatalf("Named() error = %v", err)
+	}
+
+	env, err := mgr.Environment(sel)
+	if err != nil {
+		t.Fatalf("Environment() error = %v", err)
+	}
+	if env["NOMAD_HTTP_AUTH"] != "ops" {
+		t.Fatalf("NOMAD_HTTP_AUTH = %q, want username only without a stored password", env["NOMAD_HTTP_AUTH"])
+	}
+
+	if err := mgr.SetHTTPPassword("edge", "hunter2"); err != nil {
+		t.Fatalf("SetHTTPPassword() error = %v", err)
+	}
+
+	env, err = mgr.Environment(sel)
+	if err != nil {
+		t.Fatalf("Environment() error = %v", err)
+	}
+	if env["NOMAD_HTTP_AUTH"] != "ops:hunter2" {
+		t.Fatalf("NOMAD_HTTP_AUTH = %q, want %q", env["NOMAD_HTTP_AUTH"], "ops:hunter2")
+	}
+
+	path, err := config.Path()
+	if err != nil {
+		t.Fatalf("Path() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(data), "hunter2") {
+		t.Fatalf("password leaked into %s", path)
+	}
+}
+
+func TestManagerEnvironmentCompanions(t *testing.T) {
+	mgr := newTestManager(t)
+
+	parent := &config.Context{
+		Name:    "prod",
+		Address: "https://prod",
+		Consul:  &config.ConsulSettings{Address: "https://consul.prod", Datacenter: "dc1"},
+		Vault:   &config.VaultSettings{Address: "https://vault.prod", TokenSource: contexts.VaultTokenKeyring},
+	}
+	if err := mgr.Put(parent, ""); err != nil {
+		t.Fatalf("Put(prod) error = %v", err)
+	}
+	if err := mgr.SetConsulToken("prod", "consul-secret"); err != nil {
+		t.Fatalf("SetConsulToken() error = %v", err)
+	}
+	if err := mgr.SetVaultToken("prod", "vault-secret"); err != nil {
+		t.Fatalf("SetVaultToken() error = %v", err)
+	}
+	child := &config.Context{
+		Name:   "prod/payments",
+		Parent: "prod",
+		Vault:  &config.VaultSettings{Namespace: "payments"},
+	}
+	if err := mgr.Put(child, ""); err != nil {
+		t.Fatalf("Put(prod/payments) error = %v", err)
+	}
+
+	sel, err := mgr.Named("prod/payments")
+	if err != nil {
+		t.Fatalf("Named() error = %v", err)
+	}
+	env, err := mgr.Environment(sel)
+	if err != nil {
+		t.Fatalf("Environment() error = %v", err)
+	}
+
+	want := map[string]string{
+		"CONSUL_HTTP_ADDR":  "https://consul.prod",
+		"CONSUL_DATACENTER": "dc1",
+		"CONSUL_HTTP_TOKEN": "consul-secret",
+		"VAULT_ADDR":        "https://vault.prod",
+		"VAULT_NAMESPACE":   "payments",
+		"VAULT_TOKEN":       "vault-secret",
+	}
+	for key, value := range want {
+		if env[key] != value {
+			t.Fatalf("env[%s] = %q, want %q", key, env[key], value)
+		}
+	}
+
+	t.Setenv("TEST_VAULT_TOKEN", "from-env")
+	if err := mgr.Put(&config.Context{
+		Name:   "prod/payments",
+		Parent: "prod",
+		Vault:  &config.VaultSettings{TokenSource: "env:TEST_VAULT_TOKEN"},
+	}, ""); err != nil {
+		t.Fatalf("Put(prod/payments) error = %v", err)
+	}
+	if sel, err = mgr.Named("prod/payments"); err != nil {
+		t.Fatalf("Named() error = %v", err)
+	}
+	if env, err = mgr.Environment(sel); err != nil {
+		t.Fatalf("Environment() error = %v", err)
+	}
+	if env["VAULT_TOKEN"] != "from-env" {
+		t.Fatalf("VAULT_TOKEN = %q, want %q", env["VAULT_TOKEN"], "from-env")
+	}
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := mgr.Put(&config.Context{
+		Name:   "prod/payments",
+		Parent: "prod",
+		Vault:  &config.VaultSettings{TokenSource: "file:" + tokenFile},
+	}, ""); err != nil {
+		t.Fatalf("Put(prod/payments) error = %v", err)
+	}
+	if sel, err = mgr.Named("prod/payments"); err != nil {
+		t.Fatalf("Named() error = %v", err)
+	}
+	if env, err = mgr.Environment(sel); err != nil {
+		t.Fatalf("Environment() error = %v", err)
+	}
+	if env["VAULT_TOKEN"] != "from-file" {
+		t.Fatalf("VAULT_TOKEN = %q, want %q", env["VAULT_TOKEN"], "from-file")
+	}
+}
+
+func TestValidateVaultTokenSource(t *testing.T) {
+	for _, source := range []string{"", "keyring", "env:VAULT_TOKEN", "file:~/.vault-token"} {
+		if err := contexts.ValidateVaultTokenSource(source); err != nil {
+			t.Fatalf("ValidateVaultTokenSource(%q) error = %v", source, err)
+		}
+	}
+	for _, source := range []string{"keyring:x", "env:", "file:", "vault"} {
+		if err := contexts.ValidateVaultTokenSource(source); err == nil {
+			t.Fatalf("ValidateVaultTokenSource(%q) expected error", source)
+		}
+	}
+}
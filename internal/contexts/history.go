@@ -0,0 +1,60 @@
+package contexts
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+)
+
+const maxHistory = 20
+
+var ErrNoPrevious = errors.New("no previous context")
+
+func (m *Manager) UsePrevious() (string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+
+	name := cfg.Previous
+	if name == "" {
+		return "", ErrNoPrevious
+	}
+	if _, ok := cfg.Contexts[name]; !ok {
+		return "", fmt.Errorf("%w: %s (previous context)", ErrContextNotFound, name)
+	}
+
+	recordSwitch(cfg, name, time.Now())
+	return name, config.Save(cfg)
+}
+
+func (m *Manager) History() ([]config.HistoryEntry, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]config.HistoryEntry, 0, len(cfg.History))
+	for i := len(cfg.History) - 1; i >= 0; i-- {
+		history = append(history, cfg.History[i])
+	}
+	return history, nil
+}
+
+// Selecting the context that is already current changes nothing.
+func recordSwitch(cfg *config.Config, name string, at time.Time) {
+	if cfg.Current == name {
+		return
+	}
+	if cfg.Current != "" {
+		cfg.Previous = cfg.Current
+	}
+	cfg.Current = name
+
+	cfg.History = append(cfg.History, config.HistoryEntry{Context: name, SwitchedAt: at.UTC().Truncate(time.Second)})
+	if extra := len(cfg.History) - maxHistory; extra > 0 {
+		cfg.History = append([]config.HistoryEntry(nil), cfg.History[extra:]...)
+	}
+}
@@ -0,0 +1,86 @@
+package contexts_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/contexts"
+)
+
+func TestManagerUsePrevious(t *testing.T) {
+	mgr := newTestManager(t)
+
+	for _, name := range []string{"staging", "prod", "dev"} {
+		if err := mgr.Upsert(name, "https://"+name, ""); err != nil {
+			t.Fatalf("Upsert(%s) error = %v", name, err)
+		}
+	}
+	if _, err := mgr.UsePrevious(); !errors.Is(err, contexts.ErrNoPrevious) {
+		t.Fatalf("UsePrevious() error = %v, want ErrNoPrevious", err)
+	}
+
+	if err := mgr.Use("prod"); err != nil {
+		t.Fatalf("Use(prod) error = %v", err)
+	}
+	if err := mgr.Use("prod"); err != nil {
+		t.Fatalf("Use(prod) error = %v", err)
+	}
+
+	name, err := mgr.UsePrevious()
+	if err != nil {
+		t.Fatalf("UsePrevious() error = %v", err)
+	}
+	if name != "staging" {
+		t.Fatalf("UsePrevious() = %q, want staging", name)
+	}
+	if name, _ = mgr.UsePrevious(); name != "prod" {
+		t.Fatalf("UsePrevious() = %q, want prod", name)
+	}
+
+	history, err := mgr.History()
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	want := []string{"prod", "staging", "prod"}
+	if len(history) != len(want) {
+		t.Fatalf("History() = %+v, want %v", history, want)
+	}
+	for i, entry := range history {
+		if entry.Context != want[i] || entry.SwitchedAt.IsZero() {
+			t.Fatalf("History()[%d] = %+v, want %s", i, entry, want[i])
+		}
+	}
+
+	if err := mgr.Delete("staging"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := mgr.UsePrevious(); !errors.Is(err, contexts.ErrNoPrevious) {
+		t.Fatalf("UsePrevious() after deleting it error = %v, want ErrNoPrevious", err)
+	}
+}
+
+func TestManagerHistoryIsBounded(t *testing.T) {
+	mgr := newTestManager(t)
+
+	for _, name := range []string{"a", "b"} {
+		if err := mgr.Upsert(name, "https://"+name, ""); err != nil {
+			t.Fatalf("Upsert(%s) error = %v", name, err)
+		}
+	}
+	for i := 0; i < 50; i++ {
+		if _, err := mgr.UsePrevious(); err != nil && !errors.Is(err, contexts.ErrNoPrevious) {
+			t.Fatalf("UsePrevious() error = %v", err)
+		}
+		if err := mgr.Use("b"); err != nil {
+			t.Fatalf("Use() error = %v", err)
+		}
+	}
+
+	history, err := mgr.History()
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(history) != 20 {
+		t.Fatalf("expected history to be capped at 20 entries, got %d", len(history))
+	}
+}
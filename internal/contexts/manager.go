@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/zalando/go-keyring"
 
@@ -45,12 +46,24 @@ func (m *Manager) List() ([]*config.Context, string, error) {
 	return contexts, cfg.Current, nil
 }
 
+// Names cannot contain ":", which separates the kind of secret in keyring
+// account names.
+func ValidateName(name string) error {
+	if name == "" {
+		return errors.New("context name is required")
+	}
+	if strings.Contains(name, ":") {
+		return fmt.Errorf("invalid context name %q: names cannot contain \":\"", name)
+	}
+	return nil
+}
+
 func (m *Manager) Upsert(name, address, token string) error {
 	name = strings.TrimSpace(name)
 	address = strings.TrimSpace(address)
 
-	if name == "" {
-		return errors.New("context name is required")
+	if err := ValidateName(name); err != nil {
+		return err
 	}
 
 	cfg, err := config.Load()
@@ -67,9 +80,13 @@ func (m *Manager) Upsert(name, address, token string) error {
 		}
 	}
 
-	cfg.Contexts[name] = &config.Context{
-		Name:    name,
-		Address: address,
+	if exists {
+		existing.Address = address
+	} else {
+		cfg.Contexts[name] = &config.Context{
+			Name:    name,
+			Address: address,
+		}
 	}
 
 	if cfg.Current == "" {
@@ -87,31 +104,99 @@ func (m *Manager) Upsert(name, address, token string) error {
 	return nil
 }
 
+func (m *Manager) Put(ctx *config.Context, token string) error {
+	if ctx == nil {
+		return errors.New("context is nil")
+	}
+
+	stored := *ctx
+	stored.Name = strings.TrimSpace(stored.Name)
+	stored.Address = strings.TrimSpace(stored.Address)
+	stored.Parent = strings.TrimSpace(stored.Parent)
+	if err := ValidateName(stored.Name); err != nil {
+		return err
+	}
+	if stored.Address == "" && stored.Parent == "" {
+		return errors.New("address is required")
+	}
+	for key := range stored.Env {
+		if err := ValidateEnvKey(key); err != nil {
+			return fmt.Errorf("context %q: %w", stored.Name, err)
+		}
+	}
+	for _, key := range stored.SecretEnv {
+		if err := ValidateEnvKey(key); err != nil {
+			return fmt.Errorf("context %q: %w", stored.Name, err)
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	cfg.Contexts[stored.Name] = &stored
+	if _, err := resolve(cfg, stored.Name); err != nil {
+		return err
+	}
+	if cfg.Current == "" {
+		cfg.Current = stored.Name
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+
+	if token != "" {
+		return m.SaveToken(stored.Name, token)
+	}
+	return nil
+}
+
+// Update never touches stored secrets.
+func (m *Manager) Update(fn func(cfg *config.Config) error) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(cfg); err != nil {
+		return err
+	}
+
+	return config.Save(cfg)
+}
+
 func (m *Manager) Delete(name string) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
 
-	if _, ok := cfg.Contexts[name]; !ok {
+	removed, ok := cfg.Contexts[name]
+	if !ok {
 		return fmt.Errorf("%w: %s", ErrContextNotFound, name)
 	}
 
+	if dependents := children(cfg, name); len(dependents) > 0 {
+		sort.Strings(dependents)
+		return fmt.Errorf("context %q is the parent of %s; update or delete them first", name, strings.Join(dependents, ", "))
+	}
+
 	delete(cfg.Contexts, name)
 
 	if cfg.Current == name {
 		cfg.Current = pickNewCurrent(cfg.Contexts)
 	}
-
-	if err := config.Save(cfg); err != nil {
-		return err
+	if cfg.Previous == name || cfg.Previous == cfg.Current {
+		cfg.Previous = ""
 	}
 
-	if err := keyring.Delete(m.service, name); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+	if err := config.Save(cfg); err != nil {
 		return err
 	}
 
-	return nil
+	return m.deleteSecrets(removed)
 }
 
 func (m *Manager) Use(name string) error {
@@ -124,7 +209,7 @@ func (m *Manager) Use(name string) error {
 		return fmt.Errorf("%w: %s", ErrContextNotFound, name)
 	}
 
-	cfg.Current = name
+	recordSwitch(cfg, name, time.Now())
 	return config.Save(cfg)
 }
 
@@ -164,6 +249,14 @@ func (m *Manager) Resolve(name string) (*config.Context, error) {
 	return ctx, nil
 }
 
+func (m *Manager) TrustedCatalogKeys() (map[string]string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return cfg.TrustedCatalogKeys, nil
+}
+
 func (m *Manager) SaveToken(name, token string) error {
 	name = strings.TrimSpace(name)
 	if name == "" {
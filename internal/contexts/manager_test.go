@@ -6,6 +6,7 @@ import (
 
 	"github.com/zalando/go-keyring"
 
+	"github.com/brianmichel/nomad-context/internal/config"
 	"github.com/brianmichel/nomad-context/internal/contexts"
 )
 
@@ -122,6 +123,93 @@ func newTestManager(t *testing.T) *contexts.Manager {
 	t.Helper()
 	dir := t.TempDir()
 	t.Setenv("NOMAD_CONTEXT_HOME", dir)
+	t.Setenv(contexts.ContextEnv, "")
 	keyring.MockInit()
 	return contexts.NewManager()
 }
+
+func TestManagerPutReplacesDefinition(t *testing.T) {
+	mgr := newTestManager(t)
+
+	if err := mgr.Put(&config.Context{Name: "prod/payments", Address: "https://prod", Namespace: "payments", Region: "eu"}, "prod-token"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	sel, err := mgr.Named("prod/payments")
+	if err != nil {
+		t.Fatalf("Named() error = %v", err)
+	}
+	if sel.Namespace != "payments" || sel.Region != "eu" {
+		t.Fatalf("expected namespace and region from context, got %+v", sel)
+	}
+
+	token, err := mgr.Token("prod/payments")
+	if err != nil || token != "prod-token" {
+		t.Fatalf("Token() = %q, %v", token, err)
+	}
+
+	if err := mgr.Put(&config.Context{Name: "prod/payments", Address: "https://prod"}, ""); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	ctx, err := mgr.Resolve("prod/payments")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if ctx.Namespace != "" {
+		t.Fatalf("expected Put() to replace the definition, got %+v", ctx)
+	}
+
+	if err := mgr.Put(&config.Context{Name: "empty"}, ""); err == nil {
+		t.Fatalf("expected Put() without an address to fail")
+	}
+}
+
+func TestManagerPutRejectsInvalidEnvKeys(t *testing.T) {
+	mgr := newTestManager(t)
+
+	for _, key := range []string{"A$(touch${IFS}/tmp/pwned)", "1ABC", "FOO-BAR", "A B", "X=Y"} {
+		ctx := &config.Context{Name: "dev", Address: "https://dev", Env: map[string]string{key: "v"}}
+		if err := mgr.Put(ctx, ""); err == nil {
+			t.Fatalf("Put() accepted env key %q", key)
+		}
+		ctx = &config.Context{Name: "dev", Address: "https://dev", SecretEnv: []string{key}}
+		if err := mgr.Put(ctx, ""); err == nil {
+			t.Fatalf("Put() accepted secret env key %q", key)
+		}
+	}
+
+	ctx := &config.Context{Name: "dev", Address: "https://dev", Env: map[string]string{"_PRIVATE_1": "v", "CONSUL_HTTP_ADDR": "https://consul"}}
+	if err := mgr.Put(ctx, ""); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+}
+
+func TestManagerRejectsSecretAccountNames(t *testing.T) {
+	mgr := newTestManager(t)
+
+	if err := mgr.Put(&config.Context{Name: "prod", Address: "https://prod", HTTPAuthUser: "ops"}, "prod-token"); err != nil {
+		t.Fatalf("Put(prod) error = %v", err)
+	}
+	if err := mgr.SetHTTPPassword("prod", "hunter2"); err != nil {
+		t.Fatalf("SetHTTPPassword() error = %v", err)
+	}
+
+	// "prod:http-auth" is the account holding prod's password; as a context
+	// name its token lookup would return that password.
+	if err := mgr.Put(&config.Context{Name: "prod:http-auth", Address: "https://evil"}, ""); err == nil {
+		t.Fatalf("Put() accepted a context name with a colon")
+	}
+	if err := mgr.Upsert("prod:http-auth", "https://evil", ""); err == nil {
+		t.Fatalf("Upsert() accepted a context name with a colon")
+	}
+	if err := mgr.Copy("prod", "prod:consul-token"); err == nil {
+		t.Fatalf("Copy() accepted a context name with a colon")
+	}
+	if err := contexts.ValidateContext(&config.Context{Name: "a:b", Address: "https://a"}); err == nil {
+		t.Fatalf("ValidateContext() accepted a context name with a colon")
+	}
+
+	if password, err := mgr.HTTPPassword("prod"); err != nil || password != "hunter2" {
+		t.Fatalf("HTTPPassword(prod) = %q, %v", password, err)
+	}
+}
@@ -0,0 +1,155 @@
+package contexts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+)
+
+var ErrContextExists = errors.New("context already exists")
+
+func (m *Manager) Rename(from, to string) error {
+	return m.transfer(from, to, true)
+}
+
+func (m *Manager) Copy(from, to string) error {
+	return m.transfer(from, to, false)
+}
+
+// transfer copies the secrets first and removes the copies again if any step
+// before saving the config fails, so a failed call leaves nothing behind.
+func (m *Manager) transfer(from, to string, move bool) error {
+	to = strings.TrimSpace(to)
+	if to == "" {
+		return errors.New("new context name is required")
+	}
+	if err := ValidateName(to); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	source, ok := cfg.Contexts[from]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrContextNotFound, from)
+	}
+	if _, exists := cfg.Contexts[to]; exists {
+		return fmt.Errorf("%w: %s", ErrContextExists, to)
+	}
+
+	copied, err := m.copySecrets(source, to)
+	if err != nil {
+		return err
+	}
+
+	target := cloneContext(source)
+	target.Name = to
+	// The copy is no longer the entry the catalog publishes.
+	target.Catalog = ""
+	target.CatalogRemoved = false
+	cfg.Contexts[to] = target
+
+	if move {
+		delete(cfg.Contexts, from)
+		renameReferences(cfg, from, to)
+	}
+
+	if _, err := resolve(cfg, to); err != nil {
+		m.removeAccounts(copied)
+		return err
+	}
+	if err := config.Save(cfg); err != nil {
+		m.removeAccounts(copied)
+		return err
+	}
+
+	if move {
+		if err := m.deleteSecrets(source); err != nil {
+			return fmt.Errorf("renamed %s to %s but could not remove its old secrets: %w", from, to, err)
+		}
+	}
+	return nil
+}
+
+// On failure the accounts written so far are removed.
+func (m *Manager) copySecrets(ctx *config.Context, name string) ([]string, error) {
+	sources := secretAccounts(ctx, ctx.Name)
+	targets := secretAccounts(ctx, name)
+
+	var copied []string
+	for i, account := range sources {
+		value, err := keyring.Get(m.service, account)
+		if errors.Is(err, keyring.ErrNotFound) {
+			// Drop leftovers from an earlier context with the new name so
+			// they are not picked up by the copy.
+			if err := m.deleteSecret(targets[i]); err != nil {
+				m.removeAccounts(copied)
+				return nil, err
+			}
+			continue
+		}
+		if err == nil {
+			err = keyring.Set(m.service, targets[i], value)
+		}
+		if err != nil {
+			m.removeAccounts(copied)
+			return nil, fmt.Errorf("copy secrets of %s: %w", ctx.Name, err)
+		}
+		copied = append(copied, targets[i])
+	}
+	return copied, nil
+}
+
+// removeAccounts is used for rollback; errors are ignored because the
+// original failure is what gets reported.
+func (m *Manager) removeAccounts(accounts []string) {
+	for _, account := range accounts {
+		_ = m.deleteSecret(account)
+	}
+}
+
+func renameReferences(cfg *config.Config, from, to string) {
+	if cfg.Current == from {
+		cfg.Current = to
+	}
+	if cfg.Previous == from {
+		cfg.Previous = to
+	}
+	for i := range cfg.History {
+		if cfg.History[i].Context == from {
+			cfg.History[i].Context = to
+		}
+	}
+	for _, ctx := range cfg.Contexts {
+		if ctx.Parent == from {
+			ctx.Parent = to
+		}
+	}
+}
+
+func cloneContext(ctx *config.Context) *config.Context {
+	clone := *ctx
+	if ctx.Consul != nil {
+		consul := *ctx.Consul
+		clone.Consul = &consul
+	}
+	if ctx.Vault != nil {
+		vault := *ctx.Vault
+		clone.Vault = &vault
+	}
+	if ctx.Env != nil {
+		clone.Env = make(map[string]string, len(ctx.Env))
+		for key, value := range ctx.Env {
+			clone.Env[key] = value
+		}
+	}
+	clone.SecretEnv = append([]string(nil), ctx.SecretEnv...)
+	return &clone
+}
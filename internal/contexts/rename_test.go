@@ -0,0 +1,118 @@
+package contexts_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/contexts"
+)
+
+func TestManagerRenameMovesSecretsAndReferences(t *testing.T) {
+	mgr := newTestManager(t)
+
+	if err := mgr.Put(&config.Context{Name: "staging", Address: "https://staging", HTTPAuthUser: "ops"}, "staging-token"); err != nil {
+		t.Fatalf("Put(staging) error = %v", err)
+	}
+	if err := mgr.SetHTTPPassword("staging", "hunter2"); err != nil {
+		t.Fatalf("SetHTTPPassword() error = %v", err)
+	}
+	if err := mgr.SetSecretEnv("staging", "API_KEY", "k"); err != nil {
+		t.Fatalf("SetSecretEnv() error = %v", err)
+	}
+	if err := mgr.Put(&config.Context{Name: "staging/web", Parent: "staging"}, ""); err != nil {
+		t.Fatalf("Put(staging/web) error = %v", err)
+	}
+
+	if err := mgr.Rename("staging", "stage"); err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+
+	if _, err := mgr.Resolve("staging"); !errors.Is(err, contexts.ErrContextNotFound) {
+		t.Fatalf("expected old name to be gone, got %v", err)
+	}
+	current, err := mgr.Current()
+	if err != nil || current.Name != "stage" {
+		t.Fatalf("Current() = %+v, %v; want stage", current, err)
+	}
+	child, err := mgr.Resolve("staging/web")
+	if err != nil || child.Parent != "stage" {
+		t.Fatalf("expected child to follow the rename, got %+v, %v", child, err)
+	}
+
+	if token, err := mgr.Token("stage"); err != nil || token != "staging-token" {
+		t.Fatalf("Token(stage) = %q, %v", token, err)
+	}
+	if password, err := mgr.HTTPPassword("stage"); err != nil || password != "hunter2" {
+		t.Fatalf("HTTPPassword(stage) = %q, %v", password, err)
+	}
+	if value, err := mgr.SecretEnvValue("stage", "API_KEY"); err != nil || value != "k" {
+		t.Fatalf("SecretEnvValue(stage) = %q, %v", value, err)
+	}
+	if _, err := mgr.Token("staging"); !errors.Is(err, contexts.ErrTokenNotFound) {
+		t.Fatalf("expected old token to be removed, got %v", err)
+	}
+	if _, err := mgr.SecretEnvValue("staging", "API_KEY"); !errors.Is(err, contexts.ErrSecretNotFound) {
+		t.Fatalf("expected old secret env to be removed, got %v", err)
+	}
+}
+
+func TestManagerCopyKeepsSource(t *testing.T) {
+	mgr := newTestManager(t)
+
+	if err := mgr.Put(&config.Context{Name: "prod", Address: "https://prod", Catalog: "https://catalog", Env: map[string]string{"A": "1"}}, "prod-token"); err != nil {
+		t.Fatalf("Put(prod) error = %v", err)
+	}
+	if err := mgr.Upsert("other", "https://other", ""); err != nil {
+		t.Fatalf("Upsert(other) error = %v", err)
+	}
+
+	if err := mgr.Copy("prod", "other"); !errors.Is(err, contexts.ErrContextExists) {
+		t.Fatalf("Copy() onto existing context error = %v, want ErrContextExists", err)
+	}
+	if err := mgr.Copy("prod", "prod-eu"); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+
+	for _, name := range []string{"prod", "prod-eu"} {
+		if token, err := mgr.Token(name); err != nil || token != "prod-token" {
+			t.Fatalf("Token(%s) = %q, %v", name, token, err)
+		}
+	}
+	copied, err := mgr.Resolve("prod-eu")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if copied.Address != "https://prod" || copied.Env["A"] != "1" || copied.Catalog != "" {
+		t.Fatalf("unexpected copy %+v", copied)
+	}
+	current, _ := mgr.Current()
+	if current.Name != "prod" {
+		t.Fatalf("expected current context to be unchanged, got %s", current.Name)
+	}
+}
+
+func TestManagerRenameRollsBackOnSecretFailure(t *testing.T) {
+	mgr := newTestManager(t)
+
+	if err := mgr.Upsert("dev", "https://dev", ""); err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+
+	keyring.MockInitWithError(errors.New("keyring locked"))
+	t.Cleanup(keyring.MockInit)
+
+	if err := mgr.Rename("dev", "development"); err == nil {
+		t.Fatalf("expected Rename() to fail when the keyring is unavailable")
+	}
+
+	list, current, err := mgr.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 1 || list[0].Name != "dev" || current != "dev" {
+		t.Fatalf("expected config to be unchanged, got %d contexts, current %q", len(list), current)
+	}
+}
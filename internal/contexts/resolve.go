@@ -0,0 +1,325 @@
+package contexts
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+)
+
+var ErrParentCycle = errors.New("context parent chain contains a cycle")
+
+type Resolved struct {
+	Context *config.Context
+	Chain   []string
+	// Environment variables and tags are recorded as "env.<KEY>" and
+	// "tags.<KEY>".
+	Sources         map[string]string
+	SecretEnvOwners map[string]string
+	// Err is set by ResolveAll for a context that cannot be resolved; Context
+	// then holds the stored definition.
+	Err error
+}
+
+type inheritedField struct {
+	name string
+	get  func(*config.Context) string
+	set  func(*config.Context, string)
+}
+
+var inheritedFields = []inheritedField{
+	{
+		name: "address",
+		get:  func(c *config.Context) string { return c.Address },
+		set:  func(c *config.Context, v string) { c.Address = v },
+	},
+	{
+		name: "namespace",
+		get:  func(c *config.Context) string { return c.Namespace },
+		set:  func(c *config.Context, v string) { c.Namespace = v },
+	},
+	{
+		name: "region",
+		get:  func(c *config.Context) string { return c.Region },
+		set:  func(c *config.Context, v string) { c.Region = v },
+	},
+	{
+		name: "http_auth_user",
+		get:  func(c *config.Context) string { return c.HTTPAuthUser },
+		set:  func(c *config.Context, v string) { c.HTTPAuthUser = v },
+	},
+	{
+		name: "consul.address",
+		get:  func(c *config.Context) string { return consulSettings(c).Address },
+		set:  func(c *config.Context, v string) { ensureConsul(c).Address = v },
+	},
+	{
+		name: "consul.datacenter",
+		get:  func(c *config.Context) string { return consulSettings(c).Datacenter },
+		set:  func(c *config.Context, v string) { ensureConsul(c).Datacenter = v },
+	},
+	{
+		name: "vault.address",
+		get:  func(c *config.Context) string { return vaultSettings(c).Address },
+		set:  func(c *config.Context, v string) { ensureVault(c).Address = v },
+	},
+	{
+		name: "vault.namespace",
+		get:  func(c *config.Context) string { return vaultSettings(c).Namespace },
+		set:  func(c *config.Context, v string) { ensureVault(c).Namespace = v },
+	},
+	{
+		name: "vault.token_source",
+		get:  func(c *config.Context) string { return vaultSettings(c).TokenSource },
+		set:  func(c *config.Context, v string) { ensureVault(c).TokenSource = v },
+	},
+	{
+		name: "nomad_path",
+		get:  func(c *config.Context) string { return c.NomadPath },
+		set:  func(c *config.Context, v string) { c.NomadPath = v },
+	},
+	{
+		name: "nomad_version",
+		get:  func(c *config.Context) string { return c.NomadVersion },
+		set:  func(c *config.Context, v string) { c.NomadVersion = v },
+	},
+}
+
+func consulSettings(c *config.Context) config.ConsulSettings {
+	if c.Consul == nil {
+		return config.ConsulSettings{}
+	}
+	return *c.Consul
+}
+
+func ensureConsul(c *config.Context) *config.ConsulSettings {
+	if c.Consul == nil {
+		c.Consul = &config.ConsulSettings{}
+	}
+	return c.Consul
+}
+
+func vaultSettings(c *config.Context) config.VaultSettings {
+	if c.Vault == nil {
+		return config.VaultSettings{}
+	}
+	return *c.Vault
+}
+
+func ensureVault(c *config.Context) *config.VaultSettings {
+	if c.Vault == nil {
+		c.Vault = &config.VaultSettings{}
+	}
+	return c.Vault
+}
+
+func (r *Resolved) Source(field string) string {
+	return r.Sources[field]
+}
+
+func (r *Resolved) Inherited(field string) bool {
+	source := r.Sources[field]
+	return source != "" && source != r.Context.Name
+}
+
+// secretChain stops at the link that sets the endpoint in field, so an
+// ancestor's credentials are never sent to a child's own address.
+func (r *Resolved) secretChain(field string) []string {
+	source := r.Sources[field]
+	for i, link := range r.Chain {
+		if link == source {
+			return r.Chain[:i+1]
+		}
+	}
+	return r.Chain
+}
+
+func (r *Resolved) ownsSecret(field, owner string) bool {
+	for _, link := range r.secretChain(field) {
+		if link == owner {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manager) Resolved(name string) (*Resolved, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return resolve(cfg, name)
+}
+
+// A context that cannot be resolved is returned with Err set rather than
+// failing the whole list.
+func (m *Manager) ResolveAll() ([]*Resolved, string, error) {
+	list, current, err := m.List()
+	if err != nil {
+		return nil, "", err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, "", err
+	}
+
+	resolved := make([]*Resolved, 0, len(list))
+	for _, ctx := range list {
+		r, err := resolve(cfg, ctx.Name)
+		if err != nil {
+			stored := *ctx
+			r = &Resolved{Context: &stored, Chain: []string{ctx.Name}, Sources: map[string]string{}, Err: err}
+		}
+		resolved = append(resolved, r)
+	}
+	return resolved, current, nil
+}
+
+// The second value names the context the token belongs to.
+func (m *Manager) EffectiveToken(name string) (string, string, error) {
+	r, err := m.Resolved(name)
+	if err != nil {
+		return "", "", err
+	}
+
+	token, owner, err := m.nearestSecret(r.secretChain("address"), func(link string) string { return link })
+	if err != nil {
+		return "", "", err
+	}
+	if token == "" {
+		return "", "", fmt.Errorf("%w: %s", ErrTokenNotFound, name)
+	}
+	return token, owner, nil
+}
+
+func resolve(cfg *config.Config, name string) (*Resolved, error) {
+	leaf, ok := cfg.Contexts[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrContextNotFound, name)
+	}
+
+	chain, err := parentChain(cfg, leaf)
+	if err != nil {
+		return nil, err
+	}
+
+	effective := *leaf
+	// Nested settings are rebuilt from the chain so the stored context is
+	// never modified through shared pointers.
+	effective.Consul = nil
+	effective.Vault = nil
+	resolved := &Resolved{Context: &effective, Sources: map[string]string{}}
+	for _, link := range chain {
+		resolved.Chain = append(resolved.Chain, link.Name)
+	}
+
+	for _, field := range inheritedFields {
+		for _, link := range chain {
+			if value := field.get(link); value != "" {
+				field.set(&effective, value)
+				resolved.Sources[field.name] = link.Name
+				break
+			}
+		}
+	}
+
+	resolveNomadBinary(resolved)
+	resolveEnv(chain, resolved)
+	resolveTags(chain, resolved)
+
+	if effective.Address == "" {
+		return nil, fmt.Errorf("context %q has no address in its parent chain (%s)", name, strings.Join(resolved.Chain, " -> "))
+	}
+
+	return resolved, nil
+}
+
+// The nearer of an inherited binary path and version wins, since both
+// select the binary to run.
+func resolveNomadBinary(resolved *Resolved) {
+	pathFrom, versionFrom := resolved.Sources["nomad_path"], resolved.Sources["nomad_version"]
+	if pathFrom == "" || versionFrom == "" || pathFrom == versionFrom {
+		return
+	}
+	for _, link := range resolved.Chain {
+		switch link {
+		case pathFrom:
+			resolved.Context.NomadVersion = ""
+			delete(resolved.Sources, "nomad_version")
+			return
+		case versionFrom:
+			resolved.Context.NomadPath = ""
+			delete(resolved.Sources, "nomad_path")
+			return
+		}
+	}
+}
+
+func resolveEnv(chain []*config.Context, resolved *Resolved) {
+	env := map[string]string{}
+	secrets := map[string]string{}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		link := chain[i]
+		for key, value := range link.Env {
+			env[key] = value
+			delete(secrets, key)
+			resolved.Sources["env."+key] = link.Name
+		}
+		for _, key := range link.SecretEnv {
+			secrets[key] = link.Name
+			delete(env, key)
+			resolved.Sources["env."+key] = link.Name
+		}
+	}
+
+	resolved.Context.Env = nil
+	if len(env) > 0 {
+		resolved.Context.Env = env
+	}
+
+	resolved.Context.SecretEnv = nil
+	for key := range secrets {
+		resolved.Context.SecretEnv = append(resolved.Context.SecretEnv, key)
+	}
+	sort.Strings(resolved.Context.SecretEnv)
+	resolved.SecretEnvOwners = secrets
+}
+
+func parentChain(cfg *config.Config, leaf *config.Context) ([]*config.Context, error) {
+	chain := []*config.Context{leaf}
+	seen := map[string]struct{}{leaf.Name: {}}
+
+	current := leaf
+	for current.Parent != "" {
+		parent, ok := cfg.Contexts[current.Parent]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s (parent of %s)", ErrContextNotFound, current.Parent, current.Name)
+		}
+		if _, loop := seen[parent.Name]; loop {
+			names := make([]string, 0, len(chain)+1)
+			for _, link := range chain {
+				names = append(names, link.Name)
+			}
+			names = append(names, parent.Name)
+			return nil, fmt.Errorf("%w: %s", ErrParentCycle, strings.Join(names, " -> "))
+		}
+		seen[parent.Name] = struct{}{}
+		chain = append(chain, parent)
+		current = parent
+	}
+	return chain, nil
+}
+
+func children(cfg *config.Config, name string) []string {
+	var names []string
+	for _, ctx := range cfg.Contexts {
+		if ctx.Parent == name {
+			names = append(names, ctx.Name)
+		}
+	}
+	return names
+}
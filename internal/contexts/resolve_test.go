@@ -0,0 +1,207 @@
+package contexts_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/contexts"
+)
+
+func TestManagerResolvedInheritsFromParents(t *testing.T) {
+	mgr := newTestManager(t)
+
+	if err := mgr.Put(&config.Context{Name: "prod", Address: "https://prod", Region: "us"}, "prod-token"); err != nil {
+		t.Fatalf("Put(prod) error = %v", err)
+	}
+	if err := mgr.Put(&config.Context{Name: "prod/payments", Parent: "prod", Namespace: "payments"}, ""); err != nil {
+		t.Fatalf("Put(prod/payments) error = %v", err)
+	}
+	if err := mgr.Put(&config.Context{Name: "prod/payments-eu", Parent: "prod/payments", Region: "eu"}, ""); err != nil {
+		t.Fatalf("Put(prod/payments-eu) error = %v", err)
+	}
+
+	r, err := mgr.Resolved("prod/payments-eu")
+	if err != nil {
+		t.Fatalf("Resolved() error = %v", err)
+	}
+
+	ctx := r.Context
+	if ctx.Name != "prod/payments-eu" || ctx.Address != "https://prod" || ctx.Namespace != "payments" || ctx.Region != "eu" {
+		t.Fatalf("unexpected resolved context: %+v", ctx)
+	}
+	if got := r.Source("address"); got != "prod" {
+		t.Fatalf("address source = %q, want prod", got)
+	}
+	if got := r.Source("namespace"); got != "prod/payments" {
+		t.Fatalf("namespace source = %q, want prod/payments", got)
+	}
+	if r.Inherited("region") {
+		t.Fatalf("region is set on the context itself and should not be inherited")
+	}
+
+	token, from, err := mgr.EffectiveToken("prod/payments-eu")
+	if err != nil {
+		t.Fatalf("EffectiveToken() error = %v", err)
+	}
+	if token != "prod-token" || from != "prod" {
+		t.Fatalf("EffectiveToken() = %q from %q, want prod-token from prod", token, from)
+	}
+
+	if err := mgr.Delete("prod"); err == nil {
+		t.Fatalf("expected Delete() of a parent context to fail")
+	}
+}
+
+func TestManagerRejectsParentCycles(t *testing.T) {
+	mgr := newTestManager(t)
+
+	if err := mgr.Put(&config.Context{Name: "a", Address: "https://a"}, ""); err != nil {
+		t.Fatalf("Put(a) error = %v", err)
+	}
+	if err := mgr.Put(&config.Context{Name: "b", Parent: "a"}, ""); err != nil {
+		t.Fatalf("Put(b) error = %v", err)
+	}
+
+	err := mgr.Put(&config.Context{Name: "a", Address: "https://a", Parent: "b"}, "")
+	if !errors.Is(err, contexts.ErrParentCycle) {
+		t.Fatalf("Put() error = %v, want ErrParentCycle", err)
+	}
+
+	cfg := &config.Config{
+		Current: "x",
+		Contexts: map[string]*config.Context{
+			"x": {Name: "x", Parent: "y"},
+			"y": {Name: "y", Parent: "x"},
+		},
+	}
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := mgr.Resolved("x"); !errors.Is(err, contexts.ErrParentCycle) {
+		t.Fatalf("Resolved() error = %v, want ErrParentCycle", err)
+	}
+}
+
+func TestManagerResolvedMissingParent(t *testing.T) {
+	mgr := newTestManager(t)
+
+	if err := mgr.Put(&config.Context{Name: "orphan", Parent: "missing"}, ""); !errors.Is(err, contexts.ErrContextNotFound) {
+		t.Fatalf("Put() error = %v, want ErrContextNotFound", err)
+	}
+}
+
+func TestManagerResolveAllKeepsBrokenContexts(t *testing.T) {
+	newTestManager(t)
+	mgr := contexts.NewManager()
+
+	// A hand-edited config can name a parent that does not exist.
+	cfg := &config.Config{
+		Current: "a",
+		Contexts: map[string]*config.Context{
+			"a": {Name: "a", Address: "https://a"},
+			"b": {Name: "b", Parent: "gone", Namespace: "batch"},
+		},
+	}
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	list, current, err := mgr.ResolveAll()
+	if err != nil {
+		t.Fatalf("ResolveAll() error = %v", err)
+	}
+	if current != "a" || len(list) != 2 {
+		t.Fatalf("ResolveAll() = %d contexts, current %q", len(list), current)
+	}
+	if list[0].Err != nil || list[0].Context.Address != "https://a" {
+		t.Fatalf("expected a to resolve, got %+v", list[0])
+	}
+	if !errors.Is(list[1].Err, contexts.ErrContextNotFound) || list[1].Context.Name != "b" || list[1].Context.Namespace != "batch" {
+		t.Fatalf("expected b to carry its error and stored values, got %+v (err %v)", list[1].Context, list[1].Err)
+	}
+}
+
+func TestManagerResolvedNearestNomadBinaryWins(t *testing.T) {
+	mgr := newTestManager(t)
+
+	if err := mgr.Put(&config.Context{Name: "legacy", Address: "https://legacy", NomadPath: "/opt/nomad-1.4/nomad"}, ""); err != nil {
+		t.Fatalf("Put(legacy) error = %v", err)
+	}
+	if err := mgr.Put(&config.Context{Name: "legacy/batch", Parent: "legacy", NomadVersion: "1.4.7"}, ""); err != nil {
+		t.Fatalf("Put(legacy/batch) error = %v", err)
+	}
+	if err := mgr.Put(&config.Context{Name: "legacy/web", Parent: "legacy"}, ""); err != nil {
+		t.Fatalf("Put(legacy/web) error = %v", err)
+	}
+
+	batch, err := mgr.Resolved("legacy/batch")
+	if err != nil {
+		t.Fatalf("Resolved(legacy/batch) error = %v", err)
+	}
+	if batch.Context.NomadVersion != "1.4.7" || batch.Context.NomadPath != "" {
+		t.Fatalf("expected the child's version to replace the inherited path, got path=%q version=%q", batch.Context.NomadPath, batch.Context.NomadVersion)
+	}
+	if batch.Source("nomad_path") != "" {
+		t.Fatalf("expected no source for the dropped path, got %q", batch.Source("nomad_path"))
+	}
+
+	web, err := mgr.Resolved("legacy/web")
+	if err != nil {
+		t.Fatalf("Resolved(legacy/web) error = %v", err)
+	}
+	if web.Context.NomadPath != "/opt/nomad-1.4/nomad" || !web.Inherited("nomad_path") {
+		t.Fatalf("expected inherited nomad path, got %q", web.Context.NomadPath)
+	}
+}
+
+func TestManagerDoesNotInheritSecretsAcrossAddresses(t *testing.T) {
+	mgr := newTestManager(t)
+
+	parent := &config.Context{
+		Name:         "prod",
+		Address:      "https://prod",
+		HTTPAuthUser: "ops",
+		Consul:       &config.ConsulSettings{Address: "https://consul.prod"},
+	}
+	if err := mgr.Put(parent, "prod-token"); err != nil {
+		t.Fatalf("Put(prod) error = %v", err)
+	}
+	if err := mgr.SetHTTPPassword("prod", "hunter2"); err != nil {
+		t.Fatalf("SetHTTPPassword() error = %v", err)
+	}
+	if err := mgr.SetConsulToken("prod", "consul-secret"); err != nil {
+		t.Fatalf("SetConsulToken() error = %v", err)
+	}
+	child := &config.Context{
+		Name:    "elsewhere",
+		Parent:  "prod",
+		Address: "https://elsewhere",
+		Consul:  &config.ConsulSettings{Address: "https://consul.elsewhere"},
+	}
+	if err := mgr.Put(child, ""); err != nil {
+		t.Fatalf("Put(elsewhere) error = %v", err)
+	}
+
+	if _, _, err := mgr.EffectiveToken("elsewhere"); !errors.Is(err, contexts.ErrTokenNotFound) {
+		t.Fatalf("EffectiveToken() error = %v, want ErrTokenNotFound", err)
+	}
+
+	sel, err := mgr.Named("elsewhere")
+	if err != nil {
+		t.Fatalf("Named() error = %v", err)
+	}
+	env, err := mgr.Environment(sel)
+	if err != nil {
+		t.Fatalf("Environment() error = %v", err)
+	}
+	if token, ok := env["NOMAD_TOKEN"]; ok {
+		t.Fatalf("NOMAD_TOKEN = %q inherited across addresses", token)
+	}
+	if token, ok := env["CONSUL_HTTP_TOKEN"]; ok {
+		t.Fatalf("CONSUL_HTTP_TOKEN = %q inherited across addresses", token)
+	}
+	if env["NOMAD_HTTP_AUTH"] != "ops" {
+		t.Fatalf("NOMAD_HTTP_AUTH = %q, want the user without the parent's password", env["NOMAD_HTTP_AUTH"])
+	}
+}
@@ -0,0 +1,211 @@
+package contexts
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+)
+
+var ErrSecretNotFound = errors.New("secret not found for context")
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// The token is stored under the bare context name; every other secret uses
+// a "<context>:<kind>:<key>" account so it can be found from the config.
+func envSecretAccount(name, key string) string {
+	return fmt.Sprintf("%s:env:%s", name, key)
+}
+
+func httpAuthAccount(name string) string {
+	return fmt.Sprintf("%s:http-auth", name)
+}
+
+func consulTokenAccount(name string) string {
+	return fmt.Sprintf("%s:consul-token", name)
+}
+
+func vaultTokenAccount(name string) string {
+	return fmt.Sprintf("%s:vault-token", name)
+}
+
+// Keys are written unquoted into shell statements, so only POSIX identifiers
+// are accepted.
+func ValidateEnvKey(key string) error {
+	if key == "" {
+		return errors.New("environment variable name is required")
+	}
+	if !envKeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid environment variable name %q (use letters, digits and underscores, not starting with a digit)", key)
+	}
+	return nil
+}
+
+var sensitiveEnvKeys = map[string]struct{}{
+	"PATH": {}, "IFS": {}, "BASH_ENV": {}, "ENV": {}, "GODEBUG": {},
+	"HTTP_PROXY": {}, "HTTPS_PROXY": {}, "ALL_PROXY": {}, "NO_PROXY": {},
+	"http_proxy": {}, "https_proxy": {}, "all_proxy": {}, "no_proxy": {},
+	"SSL_CERT_FILE": {}, "SSL_CERT_DIR": {},
+	"NOMAD_SKIP_VERIFY": {}, "NOMAD_CACERT": {}, "NOMAD_CAPATH": {}, "NOMAD_TLS_SERVER_NAME": {},
+	"NOMAD_CLIENT_CERT": {}, "NOMAD_CLIENT_KEY": {},
+	"CONSUL_HTTP_SSL": {}, "CONSUL_HTTP_SSL_VERIFY": {}, "CONSUL_CACERT": {}, "CONSUL_CAPATH": {},
+	"VAULT_SKIP_VERIFY": {}, "VAULT_CACERT": {}, "VAULT_CAPATH": {},
+}
+
+// SensitiveEnvKey reports keys that change which programs run or how TLS
+// connections are verified.
+func SensitiveEnvKey(key string) bool {
+	if _, ok := sensitiveEnvKeys[key]; ok {
+		return true
+	}
+	return strings.HasPrefix(key, "LD_") || strings.HasPrefix(key, "DYLD_")
+}
+
+func (m *Manager) SetSecretEnv(name, key, value string) error {
+	if err := ValidateEnvKey(key); err != nil {
+		return err
+	}
+	if value == "" {
+		return fmt.Errorf("secret value for %s is empty", key)
+	}
+
+	err := m.Update(func(cfg *config.Config) error {
+		ctx, ok := cfg.Contexts[name]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrContextNotFound, name)
+		}
+		delete(ctx.Env, key)
+		if len(ctx.Env) == 0 {
+			ctx.Env = nil
+		}
+		if !containsString(ctx.SecretEnv, key) {
+			ctx.SecretEnv = append(ctx.SecretEnv, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return keyring.Set(m.service, envSecretAccount(name, key), value)
+}
+
+func (m *Manager) SecretEnvValue(name, key string) (string, error) {
+	return m.getSecret(envSecretAccount(name, key), name, key)
+}
+
+func (m *Manager) DeleteSecretEnv(name, key string) error {
+	return m.deleteSecret(envSecretAccount(name, key))
+}
+
+func (m *Manager) SetHTTPPassword(name, password string) error {
+	return m.setSecret(name, httpAuthAccount(name), password)
+}
+
+func (m *Manager) HTTPPassword(name string) (string, error) {
+	return m.getSecret(httpAuthAccount(name), name, "http auth password")
+}
+
+func (m *Manager) DeleteHTTPPassword(name string) error {
+	return m.deleteSecret(httpAuthAccount(name))
+}
+
+func (m *Manager) SetConsulToken(name, token string) error {
+	return m.setSecret(name, consulTokenAccount(name), token)
+}
+
+func (m *Manager) ConsulToken(name string) (string, error) {
+	return m.getSecret(consulTokenAccount(name), name, "consul token")
+}
+
+func (m *Manager) DeleteConsulToken(name string) error {
+	return m.deleteSecret(consulTokenAccount(name))
+}
+
+func (m *Manager) SetVaultToken(name, token string) error {
+	return m.setSecret(name, vaultTokenAccount(name), token)
+}
+
+func (m *Manager) VaultToken(name string) (string, error) {
+	return m.getSecret(vaultTokenAccount(name), name, "vault token")
+}
+
+func (m *Manager) DeleteVaultToken(name string) error {
+	return m.deleteSecret(vaultTokenAccount(name))
+}
+
+func (m *Manager) setSecret(name, account, value string) error {
+	if value == "" {
+		return errors.New("secret value is empty")
+	}
+	if _, err := m.Resolve(name); err != nil {
+		return err
+	}
+	return keyring.Set(m.service, account, value)
+}
+
+func (m *Manager) getSecret(account, name, what string) (string, error) {
+	value, err := keyring.Get(m.service, account)
+	if err != nil {
+		if errors.Is(err, keyring.ErrNotFound) {
+			return "", fmt.Errorf("%w: %s (%s)", ErrSecretNotFound, name, what)
+		}
+		return "", err
+	}
+	return value, nil
+}
+
+func (m *Manager) deleteSecret(account string) error {
+	if err := keyring.Delete(m.service, account); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+func (m *Manager) nearestSecret(chain []string, account func(string) string) (string, string, error) {
+	for _, link := range chain {
+		value, err := keyring.Get(m.service, account(link))
+		if err == nil {
+			return value, link, nil
+		}
+		if !errors.Is(err, keyring.ErrNotFound) {
+			return "", "", err
+		}
+	}
+	return "", "", nil
+}
+
+func (m *Manager) DeleteSecrets(ctx *config.Context) error {
+	return m.deleteSecrets(ctx)
+}
+
+func (m *Manager) deleteSecrets(ctx *config.Context) error {
+	for _, account := range secretAccounts(ctx, ctx.Name) {
+		if err := m.deleteSecret(account); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The order is stable so the lists for two names line up entry by entry.
+func secretAccounts(ctx *config.Context, name string) []string {
+	accounts := []string{name, httpAuthAccount(name), consulTokenAccount(name), vaultTokenAccount(name)}
+	for _, key := range ctx.SecretEnv {
+		accounts = append(accounts, envSecretAccount(name, key))
+	}
+	return accounts
+}
+
+func containsString(values []string, want string) bool {
+	for _, value := range values {
+		if value == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package contexts
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/project"
+)
+
+const ContextEnv = "NOMAD_CONTEXT"
+
+type Source string
+
+const (
+	SourceArgument Source = "argument"
+	SourceEnv      Source = "env"
+	SourceProject  Source = "project"
+	SourceConfig   Source = "config"
+)
+
+type Selection struct {
+	Context     *config.Context
+	Resolved    *Resolved
+	Source      Source
+	ProjectFile string
+	Namespace   string
+	Region      string
+}
+
+func (s *Selection) Describe() string {
+	switch s.Source {
+	case SourceArgument:
+		return "command argument"
+	case SourceEnv:
+		return fmt.Sprintf("%s environment variable", ContextEnv)
+	case SourceProject:
+		return fmt.Sprintf("project file %s", s.ProjectFile)
+	default:
+		return "current_context in config"
+	}
+}
+
+// NOMAD_CONTEXT wins over a project file, which wins over the global current
+// context.
+func (m *Manager) Active(dir string) (*Selection, error) {
+	if name := os.Getenv(ContextEnv); name != "" {
+		sel, err := m.selection(name, SourceEnv)
+		if err != nil {
+			return nil, fmt.Errorf("%w (from %s)", err, ContextEnv)
+		}
+		return sel, nil
+	}
+
+	file, err := project.Find(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if file == nil {
+		ctx, err := m.Current()
+		if err != nil {
+			return nil, err
+		}
+		return m.selection(ctx.Name, SourceConfig)
+	}
+
+	sel, err := m.selection(file.Context, SourceProject)
+	if err != nil {
+		return nil, fmt.Errorf("%w (from %s)", err, file.Path)
+	}
+
+	sel.ProjectFile = file.Path
+	if file.Namespace != "" {
+		sel.Namespace = file.Namespace
+	}
+	if file.Region != "" {
+		sel.Region = file.Region
+	}
+	return sel, nil
+}
+
+func (m *Manager) Named(name string) (*Selection, error) {
+	return m.selection(name, SourceArgument)
+}
+
+func (m *Manager) selection(name string, source Source) (*Selection, error) {
+	resolved, err := m.Resolved(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Selection{
+		Context:   resolved.Context,
+		Resolved:  resolved,
+		Source:    source,
+		Namespace: resolved.Context.Namespace,
+		Region:    resolved.Context.Region,
+	}, nil
+}
@@ -0,0 +1,89 @@
+package contexts_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/contexts"
+	"github.com/brianmichel/nomad-context/internal/project"
+)
+
+func TestManagerActivePrefersProjectFile(t *testing.T) {
+	mgr := newTestManager(t)
+
+	if err := mgr.Upsert("dev", "https://dev", ""); err != nil {
+		t.Fatalf("Upsert(dev) error = %v", err)
+	}
+	if err := mgr.Upsert("prod", "https://prod", ""); err != nil {
+		t.Fatalf("Upsert(prod) error = %v", err)
+	}
+
+	dir := t.TempDir()
+	sel, err := mgr.Active(dir)
+	if err != nil {
+		t.Fatalf("Active() error = %v", err)
+	}
+	if sel.Context.Name != "dev" || sel.Source != contexts.SourceConfig {
+		t.Fatalf("expected global current context dev, got %q from %s", sel.Context.Name, sel.Source)
+	}
+
+	path := filepath.Join(dir, project.FileName)
+	if err := os.WriteFile(path, []byte("context = prod\nnamespace = payments\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	sel, err = mgr.Active(dir)
+	if err != nil {
+		t.Fatalf("Active() error = %v", err)
+	}
+	if sel.Context.Name != "prod" || sel.Source != contexts.SourceProject {
+		t.Fatalf("expected project context prod, got %q from %s", sel.Context.Name, sel.Source)
+	}
+	if sel.ProjectFile != path || sel.Namespace != "payments" {
+		t.Fatalf("unexpected project overrides: %+v", sel)
+	}
+}
+
+func TestManagerActiveUnknownProjectContext(t *testing.T) {
+	mgr := newTestManager(t)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, project.FileName), []byte("missing\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := mgr.Active(dir); !errors.Is(err, contexts.ErrContextNotFound) {
+		t.Fatalf("Active() error = %v, want ErrContextNotFound", err)
+	}
+}
+
+func TestManagerActivePrefersEnvironment(t *testing.T) {
+	mgr := newTestManager(t)
+
+	for _, name := range []string{"dev", "prod", "stage"} {
+		if err := mgr.Upsert(name, "https://"+name, ""); err != nil {
+			t.Fatalf("Upsert(%s) error = %v", name, err)
+		}
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, project.FileName), []byte("prod\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	t.Setenv(contexts.ContextEnv, "stage")
+	sel, err := mgr.Active(dir)
+	if err != nil {
+		t.Fatalf("Active() error = %v", err)
+	}
+	if sel.Context.Name != "stage" || sel.Source != contexts.SourceEnv || sel.ProjectFile != "" {
+		t.Fatalf("expected stage from the environment, got %q from %s", sel.Context.Name, sel.Source)
+	}
+
+	t.Setenv(contexts.ContextEnv, "missing")
+	if _, err := mgr.Active(dir); !errors.Is(err, contexts.ErrContextNotFound) {
+		t.Fatalf("Active() error = %v, want ErrContextNotFound", err)
+	}
+}
@@ -0,0 +1,165 @@
+package contexts
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+)
+
+var tagPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]*[A-Za-z0-9])?$`)
+
+func ValidateTag(key, value string) error {
+	if !tagPattern.MatchString(key) {
+		return fmt.Errorf("invalid tag key %q", key)
+	}
+	if value != "" && !tagPattern.MatchString(value) {
+		return fmt.Errorf("invalid value %q for tag %s", value, key)
+	}
+	return nil
+}
+
+func ParseTag(tag string) (string, string, error) {
+	key, value, _ := strings.Cut(strings.TrimSpace(tag), "=")
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if err := ValidateTag(key, value); err != nil {
+		return "", "", err
+	}
+	return key, value, nil
+}
+
+func FormatTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if value := tags[key]; value != "" {
+			parts = append(parts, key+"="+value)
+		} else {
+			parts = append(parts, key)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+type requirementOp int
+
+const (
+	opEquals requirementOp = iota
+	opNotEquals
+	opExists
+	opNotExists
+)
+
+type requirement struct {
+	key   string
+	op    requirementOp
+	value string
+}
+
+type Selector struct {
+	text         string
+	requirements []requirement
+}
+
+// An empty selector matches every context.
+func ParseSelector(text string) (Selector, error) {
+	sel := Selector{text: strings.TrimSpace(text)}
+	if sel.text == "" {
+		return sel, nil
+	}
+
+	for _, part := range strings.Split(sel.text, ",") {
+		part = strings.TrimSpace(part)
+		var req requirement
+		switch {
+		case part == "":
+			return Selector{}, fmt.Errorf("invalid selector %q: empty requirement", text)
+		case strings.HasPrefix(part, "!"):
+			req = requirement{key: strings.TrimSpace(part[1:]), op: opNotExists}
+		case strings.Contains(part, "!="):
+			key, value, _ := strings.Cut(part, "!=")
+			req = requirement{key: key, op: opNotEquals, value: value}
+		case strings.Contains(part, "="):
+			key, value, _ := strings.Cut(part, "=")
+			req = requirement{key: key, op: opEquals, value: strings.TrimPrefix(value, "=")}
+		default:
+			req = requirement{key: part, op: opExists}
+		}
+
+		req.key, req.value = strings.TrimSpace(req.key), strings.TrimSpace(req.value)
+		if err := ValidateTag(req.key, req.value); err != nil {
+			return Selector{}, fmt.Errorf("invalid selector %q: %w", text, err)
+		}
+		sel.requirements = append(sel.requirements, req)
+	}
+	return sel, nil
+}
+
+func (s Selector) Empty() bool {
+	return len(s.requirements) == 0
+}
+
+func (s Selector) String() string {
+	return s.text
+}
+
+func (s Selector) Matches(tags map[string]string) bool {
+	for _, req := range s.requirements {
+		value, ok := tags[req.key]
+		switch req.op {
+		case opEquals:
+			if !ok || value != req.value {
+				return false
+			}
+		case opNotEquals:
+			if ok && value == req.value {
+				return false
+			}
+		case opExists:
+			if !ok {
+				return false
+			}
+		case opNotExists:
+			if ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (s Selector) Filter(list []*Resolved) []*Resolved {
+	if s.Empty() {
+		return list
+	}
+	matched := make([]*Resolved, 0, len(list))
+	for _, r := range list {
+		if s.Matches(r.Context.Tags) {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+func resolveTags(chain []*config.Context, resolved *Resolved) {
+	tags := map[string]string{}
+	for i := len(chain) - 1; i >= 0; i-- {
+		link := chain[i]
+		for key, value := range link.Tags {
+			tags[key] = value
+			resolved.Sources["tags."+key] = link.Name
+		}
+	}
+
+	resolved.Context.Tags = nil
+	if len(tags) > 0 {
+		resolved.Context.Tags = tags
+	}
+}
@@ -0,0 +1,120 @@
+package contexts_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/contexts"
+)
+
+func TestParseSelectorMatches(t *testing.T) {
+	tags := map[string]string{"env": "prod", "team": "payments", "critical": ""}
+
+	tests := map[string]bool{
+		"":                         true,
+		"env=prod":                 true,
+		"env==prod":                true,
+		"env=prod,team=payments":   true,
+		"env=prod, team=search":    false,
+		"env!=stage":               true,
+		"env!=prod":                false,
+		"critical":                 true,
+		"cloud":                    false,
+		"!cloud":                   true,
+		"!critical":                false,
+		"cloud!=aws":               true,
+		"env=prod,!critical":       false,
+		"team=payments,cloud!=gcp": true,
+	}
+	for text, want := range tests {
+		sel, err := contexts.ParseSelector(text)
+		if err != nil {
+			t.Fatalf("ParseSelector(%q) error = %v", text, err)
+		}
+		if got := sel.Matches(tags); got != want {
+			t.Fatalf("ParseSelector(%q).Matches() = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestParseSelectorRejectsInvalid(t *testing.T) {
+	for _, text := range []string{"env=prod,", "=prod", "env=prod value", "!", "team=pay ments"} {
+		if _, err := contexts.ParseSelector(text); err == nil {
+			t.Fatalf("ParseSelector(%q) expected error", text)
+		}
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	key, value, err := contexts.ParseTag(" cloud = aws ")
+	if err != nil {
+		t.Fatalf("ParseTag() error = %v", err)
+	}
+	if key != "cloud" || value != "aws" {
+		t.Fatalf("ParseTag() = %q, %q", key, value)
+	}
+
+	if key, value, err = contexts.ParseTag("critical"); err != nil || key != "critical" || value != "" {
+		t.Fatalf("ParseTag(critical) = %q, %q, %v", key, value, err)
+	}
+	if _, _, err := contexts.ParseTag("env=prod,team=x"); err == nil {
+		t.Fatalf("ParseTag() expected error for a comma in the value")
+	}
+}
+
+func TestFormatTags(t *testing.T) {
+	got := contexts.FormatTags(map[string]string{"team": "payments", "critical": "", "env": "prod"})
+	if got != "critical,env=prod,team=payments" {
+		t.Fatalf("FormatTags() = %q", got)
+	}
+}
+
+func TestResolvedInheritsTags(t *testing.T) {
+	mgr := newTestManager(t)
+
+	if err := mgr.Put(&config.Context{Name: "prod", Address: "https://prod", Tags: map[string]string{"env": "prod", "team": "platform"}}, ""); err != nil {
+		t.Fatalf("Put(prod) error = %v", err)
+	}
+	if err := mgr.Put(&config.Context{Name: "prod/payments", Parent: "prod", Tags: map[string]string{"team": "payments"}}, ""); err != nil {
+		t.Fatalf("Put(prod/payments) error = %v", err)
+	}
+	if err := mgr.Put(&config.Context{Name: "dev", Address: "https://dev", Tags: map[string]string{"env": "dev"}}, ""); err != nil {
+		t.Fatalf("Put(dev) error = %v", err)
+	}
+
+	r, err := mgr.Resolved("prod/payments")
+	if err != nil {
+		t.Fatalf("Resolved() error = %v", err)
+	}
+	if want := map[string]string{"env": "prod", "team": "payments"}; !reflect.DeepEqual(r.Context.Tags, want) {
+		t.Fatalf("resolved tags = %v, want %v", r.Context.Tags, want)
+	}
+	if r.Source("tags.env") != "prod" || r.Inherited("tags.team") {
+		t.Fatalf("unexpected tag sources %v", r.Sources)
+	}
+
+	list, _, err := mgr.ResolveAll()
+	if err != nil {
+		t.Fatalf("ResolveAll() error = %v", err)
+	}
+	sel, err := contexts.ParseSelector("env=prod")
+	if err != nil {
+		t.Fatalf("ParseSelector() error = %v", err)
+	}
+	var names []string
+	for _, r := range sel.Filter(list) {
+		names = append(names, r.Context.Name)
+	}
+	if want := []string{"prod", "prod/payments"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("Filter() = %v, want %v", names, want)
+	}
+
+	stored, err := mgr.Resolve("prod/payments")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if len(stored.Tags) != 1 {
+		t.Fatalf("resolving modified the stored tags: %v", stored.Tags)
+	}
+}
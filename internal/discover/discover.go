@@ -0,0 +1,75 @@
+package discover
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/contexts"
+	"github.com/brianmichel/nomad-context/internal/nomadapi"
+)
+
+const defaultNamespace = "default"
+
+type Candidate struct {
+	Context *config.Context
+	Exists  bool
+}
+
+// Namespaces in the parent's own region are named "<parent>/<namespace>",
+// other regions "<parent>-<region>/<namespace>".
+func Discover(ctx context.Context, client *nomadapi.Client, parent *config.Context, existing map[string]struct{}) ([]Candidate, error) {
+	namespaces, err := client.Namespaces(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list namespaces: %w", err)
+	}
+
+	regions, err := client.Regions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list regions: %w", err)
+	}
+
+	home := parent.Region
+	if home == "" {
+		if self, err := client.AgentSelf(ctx); err == nil {
+			home = self.Config.Region
+		} else if len(regions) == 1 {
+			home = regions[0]
+		}
+	}
+
+	names := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		names = append(names, ns.Name)
+	}
+	if len(names) == 0 {
+		names = append(names, defaultNamespace)
+	}
+	sort.Strings(names)
+	sort.Strings(regions)
+
+	var candidates []Candidate
+	for _, region := range regions {
+		prefix := parent.Name
+		if region != home {
+			prefix = fmt.Sprintf("%s-%s", parent.Name, region)
+		}
+
+		for _, ns := range names {
+			derived := &config.Context{
+				Name:      fmt.Sprintf("%s/%s", prefix, ns),
+				Parent:    parent.Name,
+				Namespace: ns,
+				Region:    region,
+			}
+			if contexts.ValidateName(derived.Name) != nil {
+				continue
+			}
+			_, exists := existing[derived.Name]
+			candidates = append(candidates, Candidate{Context: derived, Exists: exists})
+		}
+	}
+
+	return candidates, nil
+}
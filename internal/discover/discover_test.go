@@ -0,0 +1,65 @@
+package discover_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/discover"
+	"github.com/brianmichel/nomad-context/internal/nomadapi"
+)
+
+func TestDiscoverDerivesContexts(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/namespaces", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Nomad-Token") != "secret" {
+			http.Error(w, "Permission denied", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`[{"Name": "payments"}, {"Name": "default"}]`))
+	})
+	mux.HandleFunc("/v1/regions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`["us", "eu"]`))
+	})
+	mux.HandleFunc("/v1/agent/self", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"config": {"Region": "us"}}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	parent := &config.Context{Name: "prod", Address: srv.URL}
+	existing := map[string]struct{}{"prod": {}, "prod/default": {}}
+
+	candidates, err := discover.Discover(context.Background(), nomadapi.New(srv.URL, nomadapi.Options{Token: "secret"}, srv.Client()), parent, existing)
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+
+	want := []struct {
+		name, namespace, region string
+		exists                  bool
+	}{
+		{"prod-eu/default", "default", "eu", false},
+		{"prod-eu/payments", "payments", "eu", false},
+		{"prod/default", "default", "us", true},
+		{"prod/payments", "payments", "us", false},
+	}
+	if len(candidates) != len(want) {
+		t.Fatalf("expected %d candidates, got %d", len(want), len(candidates))
+	}
+	for i, w := range want {
+		got := candidates[i]
+		if got.Context.Name != w.name || got.Context.Namespace != w.namespace || got.Context.Region != w.region || got.Exists != w.exists {
+			t.Fatalf("candidate %d = %+v (exists %v), want %+v", i, got.Context, got.Exists, w)
+		}
+		if got.Context.Parent != "prod" || got.Context.Address != "" {
+			t.Fatalf("candidate %q should inherit from prod, got %+v", got.Context.Name, got.Context)
+		}
+	}
+
+	if _, err := discover.Discover(context.Background(), nomadapi.New(srv.URL, nomadapi.Options{Token: ""}, srv.Client()), parent, existing); err == nil {
+		t.Fatalf("expected Discover() to fail without a valid token")
+	}
+}
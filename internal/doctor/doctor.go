@@ -0,0 +1,278 @@
+package doctor
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/brianmichel/nomad-context/internal/nomadapi"
+)
+
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip"
+)
+
+const certExpiryWarning = 14 * 24 * time.Hour
+
+type Result struct {
+	Check  string
+	Status Status
+	Detail string
+	Hint   string
+}
+
+type Options struct {
+	Timeout  time.Duration
+	Resolver *net.Resolver
+	Now      func() time.Time
+}
+
+// Once a check fails, the checks depending on it are reported as skipped.
+func Run(ctx context.Context, address string, api nomadapi.Options, opts Options) []Result {
+	if opts.Timeout == 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.Resolver == nil {
+		opts.Resolver = net.DefaultResolver
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	r := &runner{ctx: ctx, opts: opts, tls: api.TLS}
+
+	u, err := url.Parse(address)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		r.fail("address", fmt.Sprintf("invalid address %q", address), "Use a full URL such as https://nomad.example.com:4646 (ctx set --addr).")
+		r.skipRemaining("address is invalid")
+		return r.results
+	}
+
+	host := u.Hostname()
+	port := u.Port()
+	if port == "" {
+		// Match the API client, which connects to the scheme's default port.
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	r.pass("address", u.String())
+
+	if !r.checkDNS(host) {
+		r.skipRemaining("DNS resolution failed")
+		return r.results
+	}
+	if !r.checkTCP(net.JoinHostPort(host, port)) {
+		r.skipRemaining("TCP connection failed")
+		return r.results
+	}
+	if u.Scheme == "https" {
+		if !r.checkTLS(net.JoinHostPort(host, port), host) {
+			r.skipRemaining("TLS handshake failed")
+			return r.results
+		}
+	} else {
+		r.add(Result{Check: "tls", Status: StatusWarn, Detail: "address uses plain http", Hint: "Traffic, including the ACL token, is unencrypted; prefer https if the cluster supports it."})
+	}
+
+	client := nomadapi.New(u.String(), api, &http.Client{Timeout: opts.Timeout})
+
+	r.checkAPI(client, api.Token)
+	return r.results
+}
+
+type runner struct {
+	ctx     context.Context
+	opts    Options
+	tls     *tls.Config
+	results []Result
+}
+
+var allChecks = []string{"address", "dns", "tcp", "tls", "agent health", "leader", "token"}
+
+func (r *runner) add(result Result) {
+	r.results = append(r.results, result)
+}
+
+func (r *runner) pass(check, detail string) {
+	r.add(Result{Check: check, Status: StatusPass, Detail: detail})
+}
+
+func (r *runner) fail(check, detail, hint string) {
+	r.add(Result{Check: check, Status: StatusFail, Detail: detail, Hint: hint})
+}
+
+func (r *runner) skipRemaining(reason string) {
+	done := make(map[string]struct{}, len(r.results))
+	for _, result := range r.results {
+		done[result.Check] = struct{}{}
+	}
+	for _, check := range allChecks {
+		if _, ok := done[check]; ok {
+			continue
+		}
+		r.add(Result{Check: check, Status: StatusSkip, Detail: reason})
+	}
+}
+
+func (r *runner) withTimeout() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.ctx, r.opts.Timeout)
+}
+
+func (r *runner) checkDNS(host string) bool {
+	if ip := net.ParseIP(host); ip != nil {
+		r.pass("dns", fmt.Sprintf("%s is an IP address", host))
+		return true
+	}
+
+	ctx, cancel := r.withTimeout()
+	defer cancel()
+
+	addrs, err := r.opts.Resolver.LookupHost(ctx, host)
+	if err != nil {
+		r.fail("dns", err.Error(), "Check the hostname for typos and that you are on the right network or VPN.")
+		return false
+	}
+	r.pass("dns", fmt.Sprintf("%s -> %s", host, strings.Join(addrs, ", ")))
+	return true
+}
+
+func (r *runner) checkTCP(hostPort string) bool {
+	ctx, cancel := r.withTimeout()
+	defer cancel()
+
+	start := time.Now()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", hostPort)
+	if err != nil {
+		r.fail("tcp", err.Error(), "Verify the port, firewall rules and that the Nomad agent is running.")
+		return false
+	}
+	conn.Close()
+	r.pass("tcp", fmt.Sprintf("connected to %s in %s", hostPort, time.Since(start).Round(time.Millisecond)))
+	return true
+}
+
+func (r *runner) checkTLS(hostPort, serverName string) bool {
+	ctx, cancel := r.withTimeout()
+	defer cancel()
+
+	cfg := &tls.Config{}
+	if r.tls != nil {
+		cfg = r.tls.Clone()
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = serverName
+	}
+
+	dialer := tls.Dialer{Config: cfg}
+	conn, err := dialer.DialContext(ctx, "tcp", hostPort)
+	if err != nil {
+		hint := "Check that the server speaks TLS on this port and that its certificate is trusted by this machine."
+		var certErr *tls.CertificateVerificationError
+		if errors.As(err, &certErr) {
+			hint = "The certificate is not trusted or does not match the hostname; install the cluster CA or fix the address."
+		}
+		r.fail("tls", err.Error(), hint)
+		return false
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		r.fail("tls", "server presented no certificate", "Check the TLS configuration of the Nomad agent.")
+		return false
+	}
+
+	leaf := state.PeerCertificates[0]
+	remaining := leaf.NotAfter.Sub(r.opts.Now())
+	detail := fmt.Sprintf("%s, certificate valid until %s", tls.VersionName(state.Version), leaf.NotAfter.Format(time.DateOnly))
+	if remaining < certExpiryWarning {
+		r.add(Result{Check: "tls", Status: StatusWarn, Detail: detail, Hint: "The server certificate expires soon; ask the cluster operators to rotate it."})
+		return true
+	}
+	r.pass("tls", detail)
+	return true
+}
+
+func (r *runner) checkAPI(client *nomadapi.Client, token string) {
+	ctx, cancel := r.withTimeout()
+	defer cancel()
+
+	if err := client.AgentHealth(ctx); err != nil {
+		if nomadapi.IsStatusError(err) {
+			r.fail("agent health", err.Error(), "The agent answered but reports itself unhealthy; check the agent logs and server membership.")
+			r.skipRemaining("agent is unhealthy")
+		} else {
+			r.fail("agent health", err.Error(), "The agent did not answer the HTTP request; check for a proxy or load balancer in front of it and the TLS settings.")
+			r.skipRemaining("agent did not answer")
+		}
+		return
+	}
+	r.pass("agent health", "agent reports healthy")
+
+	ctx, cancel = r.withTimeout()
+	defer cancel()
+
+	leader, err := client.Leader(ctx)
+	switch {
+	case err != nil:
+		r.fail("leader", err.Error(), "The cluster may be without quorum; check the server peers.")
+	case leader == "":
+		r.fail("leader", "no leader elected", "The cluster has no leader; check that a quorum of servers is running.")
+	default:
+		r.pass("leader", leader)
+	}
+
+	r.checkToken(client, token)
+}
+
+func (r *runner) checkToken(client *nomadapi.Client, token string) {
+	if token == "" {
+		r.add(Result{Check: "token", Status: StatusSkip, Detail: "no token stored for this context", Hint: "Store one with ctx set --prompt-token if the cluster has ACLs enabled."})
+		return
+	}
+
+	ctx, cancel := r.withTimeout()
+	defer cancel()
+
+	self, err := client.TokenSelf(ctx)
+	if err != nil {
+		switch {
+		case nomadapi.ACLDisabled(err):
+			r.add(Result{Check: "token", Status: StatusWarn, Detail: "ACLs are disabled on this cluster", Hint: "The stored token is not used; remove it or enable ACLs."})
+		case nomadapi.Forbidden(err):
+			r.fail("token", "token was rejected", "The token is expired, revoked or belongs to another cluster; rotate it with ctx set --prompt-token.")
+		default:
+			r.fail("token", err.Error(), "Could not validate the token; check the agent logs.")
+		}
+		return
+	}
+
+	name := self.Name
+	if name == "" {
+		name = self.AccessorID
+	}
+	r.pass("token", fmt.Sprintf("%s token %q", self.Type, name))
+}
+
+func Failed(results []Result) bool {
+	for _, result := range results {
+		if result.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package doctor_test
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianmichel/nomad-context/internal/doctor"
+	"github.com/brianmichel/nomad-context/internal/nomadapi"
+)
+
+func fakeNomad(validToken string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/agent/health", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"server": {"ok": true, "message": "ok"}}`))
+	})
+	mux.HandleFunc("/v1/status/leader", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`"10.0.0.1:4647"`))
+	})
+	mux.HandleFunc("/v1/acl/token/self", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Nomad-Token") != validToken {
+			http.Error(w, "Permission denied", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"AccessorID": "abc", "Name": "ops", "Type": "client"}`))
+	})
+	return mux
+}
+
+func statuses(results []doctor.Result) map[string]doctor.Status {
+	byCheck := make(map[string]doctor.Status, len(results))
+	for _, result := range results {
+		byCheck[result.Check] = result.Status
+	}
+	return byCheck
+}
+
+func TestRunHealthyPlainHTTP(t *testing.T) {
+	srv := httptest.NewServer(fakeNomad("secret"))
+	defer srv.Close()
+
+	results := doctor.Run(context.Background(), srv.URL, nomadapi.Options{Token: "secret"}, doctor.Options{})
+	got := statuses(results)
+
+	want := map[string]doctor.Status{
+		"address":      doctor.StatusPass,
+		"dns":          doctor.StatusPass,
+		"tcp":          doctor.StatusPass,
+		"tls":          doctor.StatusWarn,
+		"agent health": doctor.StatusPass,
+		"leader":       doctor.StatusPass,
+		"token":        doctor.StatusPass,
+	}
+	for check, status := range want {
+		if got[check] != status {
+			t.Fatalf("check %q = %q, want %q (results %+v)", check, got[check], status, results)
+		}
+	}
+	if doctor.Failed(results) {
+		t.Fatalf("expected no failures, got %+v", results)
+	}
+}
+
+func TestRunRejectedToken(t *testing.T) {
+	srv := httptest.NewServer(fakeNomad("secret"))
+	defer srv.Close()
+
+	results := doctor.Run(context.Background(), srv.URL, nomadapi.Options{Token: "wrong"}, doctor.Options{})
+	if got := statuses(results)["token"]; got != doctor.StatusFail {
+		t.Fatalf("token check = %q, want fail", got)
+	}
+	if !doctor.Failed(results) {
+		t.Fatalf("expected Failed() to report the rejected token")
+	}
+}
+
+func TestRunTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(fakeNomad("secret"))
+	defer srv.Close()
+
+	untrusted := statuses(doctor.Run(context.Background(), srv.URL, nomadapi.Options{Token: "secret"}, doctor.Options{}))
+	if untrusted["tls"] != doctor.StatusFail || untrusted["agent health"] != doctor.StatusSkip {
+		t.Fatalf("expected untrusted certificate to fail TLS and skip API checks, got %+v", untrusted)
+	}
+
+	transport := srv.Client().Transport.(*http.Transport)
+	trusted := statuses(doctor.Run(context.Background(), srv.URL, nomadapi.Options{
+		Token: "secret",
+		TLS:   &tls.Config{RootCAs: transport.TLSClientConfig.RootCAs},
+	}, doctor.Options{}))
+	if trusted["tls"] != doctor.StatusPass || trusted["token"] != doctor.StatusPass {
+		t.Fatalf("expected trusted TLS checks to pass, got %+v", trusted)
+	}
+}
+
+func TestRunUnreachable(t *testing.T) {
+	srv := httptest.NewServer(fakeNomad(""))
+	addr := srv.URL
+	srv.Close()
+
+	got := statuses(doctor.Run(context.Background(), addr, nomadapi.Options{}, doctor.Options{}))
+	if got["tcp"] != doctor.StatusFail || got["leader"] != doctor.StatusSkip {
+		t.Fatalf("expected closed port to fail TCP and skip later checks, got %+v", got)
+	}
+}
+
+func TestRunInvalidAddress(t *testing.T) {
+	results := doctor.Run(context.Background(), "nomad.local:4646", nomadapi.Options{}, doctor.Options{})
+	if got := statuses(results)["address"]; got != doctor.StatusFail {
+		t.Fatalf("address check = %q, want fail", got)
+	}
+	if len(results) != 7 {
+		t.Fatalf("expected every check to be reported, got %d", len(results))
+	}
+}
+
+func TestRunDefaultsToSchemePort(t *testing.T) {
+	results := doctor.Run(context.Background(), "http://127.0.0.1", nomadapi.Options{}, doctor.Options{Timeout: time.Second})
+	for _, result := range results {
+		if result.Check != "tcp" {
+			continue
+		}
+		if !strings.Contains(result.Detail, "127.0.0.1:80") {
+			t.Fatalf("tcp check detail = %q, want a connection to port 80", result.Detail)
+		}
+		return
+	}
+	t.Fatalf("no tcp check in %+v", results)
+}
+
+func TestRunAgentWithoutAnswer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	defer srv.Close()
+
+	for _, result := range doctor.Run(context.Background(), srv.URL, nomadapi.Options{}, doctor.Options{}) {
+		if result.Check != "agent health" {
+			continue
+		}
+		if result.Status != doctor.StatusFail || strings.Contains(result.Hint, "answered") {
+			t.Fatalf("agent health = %+v, want a failure without the unhealthy hint", result)
+		}
+		return
+	}
+	t.Fatalf("no agent health check reported")
+}
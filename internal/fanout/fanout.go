@@ -0,0 +1,151 @@
+package fanout
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brianmichel/nomad-context/internal/parallel"
+)
+
+// Err is a failure preparing the command; the target is then reported
+// without being run.
+type Target struct {
+	Name string
+	Path string
+	Args []string
+	Env  []string
+	Err  error
+}
+
+type Result struct {
+	Name     string
+	ExitCode int
+	Duration time.Duration
+	Err      error
+}
+
+func (r Result) Failed() bool {
+	return r.ExitCode != 0 || r.Err != nil
+}
+
+type Options struct {
+	Parallel int
+	Stdout   io.Writer
+	Stderr   io.Writer
+}
+
+// Commands get no stdin.
+func Run(ctx context.Context, targets []Target, opts Options) []Result {
+	if opts.Stdout == nil {
+		opts.Stdout = io.Discard
+	}
+	if opts.Stderr == nil {
+		opts.Stderr = io.Discard
+	}
+
+	width := 0
+	for _, target := range targets {
+		width = max(width, len(target.Name))
+	}
+
+	stdout := &lockedWriter{w: opts.Stdout}
+	stderr := &lockedWriter{w: opts.Stderr}
+	if opts.Stdout == opts.Stderr {
+		stderr = stdout
+	}
+
+	return parallel.Map(targets, opts.Parallel, func(target Target) Result {
+		prefix := target.Name + strings.Repeat(" ", width-len(target.Name)) + " | "
+		out := &PrefixWriter{Prefix: prefix, W: stdout}
+		errOut := &PrefixWriter{Prefix: prefix, W: stderr}
+		result := runOne(ctx, target, out, errOut)
+		_ = out.Flush()
+		_ = errOut.Flush()
+		return result
+	})
+}
+
+func runOne(ctx context.Context, target Target, stdout, stderr io.Writer) Result {
+	result := Result{Name: target.Name, ExitCode: -1}
+	if target.Err != nil {
+		result.Err = target.Err
+		return result
+	}
+
+	command := exec.CommandContext(ctx, target.Path, target.Args...) // #nosec G204 -- arguments are provided intentionally by the user.
+	command.Env = target.Env
+	command.Stdout = stdout
+	command.Stderr = stderr
+
+	start := time.Now()
+	err := command.Run()
+	result.Duration = time.Since(start)
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		result.ExitCode = 0
+	case errors.As(err, &exitErr) && exitErr.ExitCode() >= 0:
+		result.ExitCode = exitErr.ExitCode()
+	default:
+		result.Err = err
+	}
+	return result
+}
+
+// Whole lines are written in a single call so concurrent writers sharing W
+// do not interleave within a line.
+type PrefixWriter struct {
+	Prefix string
+	W      io.Writer
+	buf    []byte
+}
+
+func (p *PrefixWriter) Write(data []byte) (int, error) {
+	p.buf = append(p.buf, data...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if err := p.writeLine(p.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[i+1:]
+	}
+	return len(data), nil
+}
+
+func (p *PrefixWriter) Flush() error {
+	if len(p.buf) == 0 {
+		return nil
+	}
+	line := append(p.buf, '\n')
+	p.buf = nil
+	return p.writeLine(line)
+}
+
+func (p *PrefixWriter) writeLine(line []byte) error {
+	out := make([]byte, 0, len(p.Prefix)+len(line))
+	out = append(out, p.Prefix...)
+	out = append(out, line...)
+	_, err := p.W.Write(out)
+	return err
+}
+
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(data []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(data)
+}
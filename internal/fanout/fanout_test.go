@@ -0,0 +1,77 @@
+package fanout_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/fanout"
+)
+
+func TestPrefixWriterPrefixesLines(t *testing.T) {
+	var out bytes.Buffer
+	w := &fanout.PrefixWriter{Prefix: "prod | ", W: &out}
+
+	for _, chunk := range []string{"first line\nsec", "ond line\n", "partial"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if got := out.String(); got != "prod | first line\nprod | second line\n" {
+		t.Fatalf("output before Flush = %q", got)
+	}
+
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if got := out.String(); !strings.HasSuffix(got, "prod | partial\n") {
+		t.Fatalf("output after Flush = %q", got)
+	}
+}
+
+func TestRunReportsExitCodes(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil || runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	targets := []fanout.Target{
+		{Name: "prod", Path: sh, Args: []string{"-c", "echo ok; echo warn >&2"}},
+		{Name: "prod-eu", Path: sh, Args: []string{"-c", "echo $REGION; exit 3"}, Env: []string{"REGION=eu"}},
+		{Name: "stage", Err: errors.New("no token")},
+		{Name: "missing", Path: "/nonexistent/nomad"},
+	}
+
+	var stdout, stderr bytes.Buffer
+	results := fanout.Run(context.Background(), targets, fanout.Options{Parallel: 2, Stdout: &stdout, Stderr: &stderr})
+
+	if len(results) != len(targets) {
+		t.Fatalf("Run() returned %d results, want %d", len(results), len(targets))
+	}
+	for i, want := range []struct {
+		code   int
+		failed bool
+	}{{0, false}, {3, true}, {-1, true}, {-1, true}} {
+		got := results[i]
+		if got.Name != targets[i].Name || got.ExitCode != want.code || got.Failed() != want.failed {
+			t.Fatalf("result %d = %+v, want exit %d", i, got, want.code)
+		}
+	}
+	if results[2].Err == nil || results[3].Err == nil {
+		t.Fatalf("expected errors for unprepared and missing commands: %+v", results)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	sort.Strings(lines)
+	if want := []string{"prod    | ok", "prod-eu | eu"}; strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("stdout = %q", stdout.String())
+	}
+	if stderr.String() != "prod    | warn\n" {
+		t.Fatalf("stderr = %q", stderr.String())
+	}
+}
@@ -0,0 +1,189 @@
+package nomadapi
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const maxErrorBody = 512
+
+type Client struct {
+	Address  string
+	Token    string
+	HTTPAuth string
+	HTTP     *http.Client
+}
+
+type Options struct {
+	Token    string
+	HTTPAuth string
+	TLS      *tls.Config
+}
+
+type StatusError struct {
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("%s: unexpected status %d", e.Path, e.StatusCode)
+	}
+	return fmt.Sprintf("%s: unexpected status %d: %s", e.Path, e.StatusCode, e.Body)
+}
+
+func New(address string, opts Options, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if opts.TLS != nil {
+		httpClient = withTLS(httpClient, opts.TLS)
+	}
+	return &Client{
+		Address:  strings.TrimRight(address, "/"),
+		Token:    opts.Token,
+		HTTPAuth: opts.HTTPAuth,
+		HTTP:     httpClient,
+	}
+}
+
+func withTLS(httpClient *http.Client, cfg *tls.Config) *http.Client {
+	transport, ok := httpClient.Transport.(*http.Transport)
+	if !ok || transport == nil {
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+	transport = transport.Clone()
+	transport.TLSClientConfig = cfg
+
+	copied := *httpClient
+	copied.Transport = transport
+	return &copied
+}
+
+func (c *Client) Get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.Address+path, nil)
+	if err != nil {
+		return err
+	}
+	if c.Token != "" {
+		req.Header.Set("X-Nomad-Token", c.Token)
+	}
+	if c.HTTPAuth != "" {
+		user, password, _ := strings.Cut(c.HTTPAuth, ":")
+		req.SetBasicAuth(user, password)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return &StatusError{Path: path, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+
+	if out == nil {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+type TokenSelf struct {
+	AccessorID string `json:"AccessorID"`
+	Name       string `json:"Name"`
+	Type       string `json:"Type"`
+}
+
+type AgentSelf struct {
+	Config struct {
+		Region     string `json:"Region"`
+		Datacenter string `json:"Datacenter"`
+		Version    struct {
+			Version           string `json:"Version"`
+			VersionPrerelease string `json:"VersionPrerelease"`
+		} `json:"Version"`
+	} `json:"config"`
+}
+
+func (a *AgentSelf) Version() string {
+	v := a.Config.Version.Version
+	if pre := a.Config.Version.VersionPrerelease; pre != "" && v != "" {
+		v += "-" + pre
+	}
+	return v
+}
+
+func (c *Client) AgentSelf(ctx context.Context) (*AgentSelf, error) {
+	var self AgentSelf
+	if err := c.Get(ctx, "/v1/agent/self", &self); err != nil {
+		return nil, err
+	}
+	return &self, nil
+}
+
+func (c *Client) AgentHealth(ctx context.Context) error {
+	return c.Get(ctx, "/v1/agent/health", nil)
+}
+
+func (c *Client) Leader(ctx context.Context) (string, error) {
+	var leader string
+	if err := c.Get(ctx, "/v1/status/leader", &leader); err != nil {
+		return "", err
+	}
+	return leader, nil
+}
+
+func (c *Client) TokenSelf(ctx context.Context) (*TokenSelf, error) {
+	var token TokenSelf
+	if err := c.Get(ctx, "/v1/acl/token/self", &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+type Namespace struct {
+	Name        string `json:"Name"`
+	Description string `json:"Description"`
+}
+
+func (c *Client) Namespaces(ctx context.Context) ([]Namespace, error) {
+	var namespaces []Namespace
+	if err := c.Get(ctx, "/v1/namespaces", &namespaces); err != nil {
+		return nil, err
+	}
+	return namespaces, nil
+}
+
+func (c *Client) Regions(ctx context.Context) ([]string, error) {
+	var regions []string
+	if err := c.Get(ctx, "/v1/regions", &regions); err != nil {
+		return nil, err
+	}
+	return regions, nil
+}
+
+// IsStatusError tells a server that answered apart from a transport failure.
+func IsStatusError(err error) bool {
+	var se *StatusError
+	return errors.As(err, &se)
+}
+
+func Forbidden(err error) bool {
+	var se *StatusError
+	return errors.As(err, &se) && se.StatusCode == http.StatusForbidden
+}
+
+func ACLDisabled(err error) bool {
+	var se *StatusError
+	return errors.As(err, &se) && se.StatusCode == http.StatusBadRequest && strings.Contains(strings.ToLower(se.Body), "acl support disabled")
+}
@@ -0,0 +1,68 @@
+package nomadapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/nomadapi"
+)
+
+func TestClientSendsBasicAuthOverTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "ops" || password != "hunter2" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("X-Nomad-Token") != "secret" {
+			http.Error(w, "Permission denied", http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`"10.0.0.1:4647"`))
+	}))
+	defer srv.Close()
+
+	opts, err := nomadapi.OptionsFromEnv(map[string]string{
+		"NOMAD_TOKEN":       "secret",
+		"NOMAD_HTTP_AUTH":   "ops:hunter2",
+		"NOMAD_SKIP_VERIFY": "true",
+	})
+	if err != nil {
+		t.Fatalf("OptionsFromEnv() error = %v", err)
+	}
+	if opts.TLS == nil || !opts.TLS.InsecureSkipVerify {
+		t.Fatalf("OptionsFromEnv() TLS = %+v, want verification skipped", opts.TLS)
+	}
+
+	leader, err := nomadapi.New(srv.URL, opts, nil).Leader(context.Background())
+	if err != nil {
+		t.Fatalf("Leader() error = %v", err)
+	}
+	if leader != "10.0.0.1:4647" {
+		t.Fatalf("Leader() = %q", leader)
+	}
+
+	opts.HTTPAuth = ""
+	if _, err := nomadapi.New(srv.URL, opts, nil).Leader(context.Background()); !nomadapi.IsStatusError(err) {
+		t.Fatalf("Leader() without basic auth error = %v, want a status error", err)
+	}
+}
+
+func TestOptionsFromEnvRejectsInvalidSettings(t *testing.T) {
+	for _, env := range []map[string]string{
+		{"NOMAD_SKIP_VERIFY": "maybe"},
+		{"NOMAD_CACERT": "/nonexistent/ca.pem"},
+		{"NOMAD_CLIENT_CERT": "/nonexistent/cert.pem"},
+	} {
+		if _, err := nomadapi.OptionsFromEnv(env); err == nil {
+			t.Fatalf("OptionsFromEnv(%v) expected error", env)
+		}
+	}
+
+	opts, err := nomadapi.OptionsFromEnv(map[string]string{"NOMAD_TOKEN": "secret"})
+	if err != nil || opts.TLS != nil {
+		t.Fatalf("OptionsFromEnv() = %+v, %v; want no TLS settings", opts, err)
+	}
+}
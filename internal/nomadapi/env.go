@@ -0,0 +1,71 @@
+package nomadapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// OptionsFromEnv reads the same variables as the nomad CLI.
+func OptionsFromEnv(env map[string]string) (Options, error) {
+	opts := Options{Token: env["NOMAD_TOKEN"], HTTPAuth: env["NOMAD_HTTP_AUTH"]}
+
+	cfg := &tls.Config{ServerName: env["NOMAD_TLS_SERVER_NAME"]}
+	custom := cfg.ServerName != ""
+
+	if value := env["NOMAD_SKIP_VERIFY"]; value != "" {
+		skip, err := strconv.ParseBool(value)
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid NOMAD_SKIP_VERIFY %q", value)
+		}
+		cfg.InsecureSkipVerify = skip // #nosec G402 -- the context explicitly disables verification, as the nomad CLI would.
+		custom = true
+	}
+
+	var caFiles []string
+	if file := env["NOMAD_CACERT"]; file != "" {
+		caFiles = append(caFiles, file)
+	}
+	if dir := env["NOMAD_CAPATH"]; dir != "" {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return Options{}, fmt.Errorf("read NOMAD_CAPATH: %w", err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				caFiles = append(caFiles, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	if len(caFiles) > 0 {
+		pool := x509.NewCertPool()
+		for _, file := range caFiles {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return Options{}, fmt.Errorf("read CA certificate: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(data) {
+				return Options{}, fmt.Errorf("no certificates found in %s", file)
+			}
+		}
+		cfg.RootCAs = pool
+		custom = true
+	}
+
+	if certFile, keyFile := env["NOMAD_CLIENT_CERT"], env["NOMAD_CLIENT_KEY"]; certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return Options{}, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+		custom = true
+	}
+
+	if custom {
+		opts.TLS = cfg
+	}
+	return opts, nil
+}
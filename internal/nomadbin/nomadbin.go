@@ -0,0 +1,143 @@
+package nomadbin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+)
+
+const (
+	PathEnv = "NOMAD_CONTEXT_NOMAD_PATH"
+	DirEnv  = "NOMAD_CONTEXT_NOMAD_DIR"
+
+	defaultBinary = "nomad"
+)
+
+var ErrVersionNotInstalled = errors.New("nomad version not installed")
+
+var versionPattern = regexp.MustCompile(`^v?\d+\.\d+(\.\d+)?([-+][0-9A-Za-z.+-]+)?$`)
+
+func Dir() (string, error) {
+	if dir := os.Getenv(DirEnv); dir != "" {
+		return expandHome(dir), nil
+	}
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bin"), nil
+}
+
+func Resolve(path, version string) (string, error) {
+	if path != "" {
+		return expandHome(path), nil
+	}
+	if version != "" {
+		return Find(version)
+	}
+	if binary := os.Getenv(PathEnv); binary != "" {
+		return binary, nil
+	}
+	return defaultBinary, nil
+}
+
+// Binaries may be named nomad_<version> or nomad-<version>, or live at
+// <version>/nomad.
+func Find(version string) (string, error) {
+	if err := ValidateVersion(version); err != nil {
+		return "", err
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	version = strings.TrimPrefix(version, "v")
+	for _, candidate := range candidates(dir, version) {
+		info, err := os.Stat(candidate)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		return candidate, nil
+	}
+
+	installed, _ := Installed(dir)
+	if len(installed) == 0 {
+		return "", fmt.Errorf("%w: %s (no versions found in %s)", ErrVersionNotInstalled, version, dir)
+	}
+	return "", fmt.Errorf("%w: %s (found %s in %s)", ErrVersionNotInstalled, version, strings.Join(installed, ", "), dir)
+}
+
+func Installed(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var versions []string
+	for _, entry := range entries {
+		name := entry.Name()
+		version := ""
+		switch {
+		case entry.IsDir():
+			if info, err := os.Stat(filepath.Join(dir, name, defaultBinary+exeSuffix())); err == nil && !info.IsDir() {
+				version = name
+			}
+		case strings.HasPrefix(name, "nomad_"), strings.HasPrefix(name, "nomad-"):
+			version = strings.TrimSuffix(name[len("nomad_"):], exeSuffix())
+		}
+		// Skips unrelated files such as nomad-context living next to the
+		// versioned binaries.
+		if versionPattern.MatchString(version) {
+			versions = append(versions, strings.TrimPrefix(version, "v"))
+		}
+	}
+	sort.Strings(versions)
+	return versions, nil
+}
+
+func ValidateVersion(version string) error {
+	if version == "" || strings.ContainsAny(version, `/\`) || strings.HasPrefix(version, ".") {
+		return fmt.Errorf("invalid nomad version %q", version)
+	}
+	return nil
+}
+
+func candidates(dir, version string) []string {
+	suffix := exeSuffix()
+	var paths []string
+	for _, v := range []string{version, "v" + version} {
+		paths = append(paths,
+			filepath.Join(dir, "nomad_"+v+suffix),
+			filepath.Join(dir, "nomad-"+v+suffix),
+			filepath.Join(dir, v, defaultBinary+suffix),
+		)
+	}
+	return paths
+}
+
+func exeSuffix() string {
+	if runtime.GOOS == "windows" {
+		return ".exe"
+	}
+	return ""
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
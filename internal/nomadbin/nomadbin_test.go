@@ -0,0 +1,109 @@
+package nomadbin_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/nomadbin"
+)
+
+func installBinary(t *testing.T, path string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		path += ".exe"
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+func TestFindInstalledVersions(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(nomadbin.DirEnv, dir)
+
+	installBinary(t, filepath.Join(dir, "nomad_1.4.7"))
+	installBinary(t, filepath.Join(dir, "nomad-1.7.2"))
+	installBinary(t, filepath.Join(dir, "v1.9.3", "nomad"))
+	installBinary(t, filepath.Join(dir, "nomad-context"))
+	installBinary(t, filepath.Join(dir, "tools", "nomad"))
+
+	tests := map[string]string{
+		"1.4.7":  "nomad_1.4.7",
+		"v1.4.7": "nomad_1.4.7",
+		"1.7.2":  "nomad-1.7.2",
+		"1.9.3":  filepath.Join("v1.9.3", "nomad"),
+	}
+	for version, want := range tests {
+		got, err := nomadbin.Find(version)
+		if err != nil {
+			t.Fatalf("Find(%q) error = %v", version, err)
+		}
+		want = filepath.Join(dir, want)
+		if runtime.GOOS == "windows" {
+			want += ".exe"
+		}
+		if got != want {
+			t.Fatalf("Find(%q) = %q, want %q", version, got, want)
+		}
+	}
+
+	versions, err := nomadbin.Installed(dir)
+	if err != nil {
+		t.Fatalf("Installed() error = %v", err)
+	}
+	if strings.Join(versions, ",") != "1.4.7,1.7.2,1.9.3" {
+		t.Fatalf("Installed() = %v", versions)
+	}
+}
+
+func TestFindMissingVersion(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(nomadbin.DirEnv, dir)
+	installBinary(t, filepath.Join(dir, "nomad_1.4.7"))
+
+	_, err := nomadbin.Find("1.9.0")
+	if !errors.Is(err, nomadbin.ErrVersionNotInstalled) {
+		t.Fatalf("Find() error = %v, want ErrVersionNotInstalled", err)
+	}
+	if !strings.Contains(err.Error(), "1.4.7") {
+		t.Fatalf("expected error to list installed versions, got %v", err)
+	}
+
+	if _, err := nomadbin.Find("../nomad"); err == nil {
+		t.Fatalf("expected invalid version to be rejected")
+	}
+}
+
+func TestResolvePrecedence(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(nomadbin.DirEnv, dir)
+	t.Setenv(nomadbin.PathEnv, "/usr/local/bin/nomad-global")
+	installBinary(t, filepath.Join(dir, "nomad_1.4.7"))
+
+	got, err := nomadbin.Resolve("/opt/nomad", "1.4.7")
+	if err != nil || got != "/opt/nomad" {
+		t.Fatalf("Resolve(path, version) = %q, %v; want the path", got, err)
+	}
+
+	got, err = nomadbin.Resolve("", "1.4.7")
+	if err != nil || !strings.HasPrefix(got, filepath.Join(dir, "nomad_1.4.7")) {
+		t.Fatalf("Resolve(version) = %q, %v; want the installed binary", got, err)
+	}
+
+	got, err = nomadbin.Resolve("", "")
+	if err != nil || got != "/usr/local/bin/nomad-global" {
+		t.Fatalf("Resolve() = %q, %v; want the global override", got, err)
+	}
+
+	t.Setenv(nomadbin.PathEnv, "")
+	if got, _ := nomadbin.Resolve("", ""); got != "nomad" {
+		t.Fatalf("Resolve() = %q, want nomad", got)
+	}
+}
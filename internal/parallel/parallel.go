@@ -0,0 +1,26 @@
+package parallel
+
+import "sync"
+
+func Map[T, R any](items []T, limit int, fn func(T) R) []R {
+	if limit < 1 {
+		limit = 1
+	}
+
+	results := make([]R, len(items))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for i, item := range items {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = fn(item)
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,36 @@
+package parallel_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/brianmichel/nomad-context/internal/parallel"
+)
+
+func TestMapKeepsOrderAndBoundsConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8}
+
+	results := parallel.Map(items, 3, func(n int) int {
+		current := running.Add(1)
+		for {
+			seen := peak.Load()
+			if current <= seen || peak.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return n * n
+	})
+
+	for i, n := range items {
+		if results[i] != n*n {
+			t.Fatalf("Map() = %v, want squares in order", results)
+		}
+	}
+	if peak.Load() > 3 {
+		t.Fatalf("Map() ran %d calls at once, want at most 3", peak.Load())
+	}
+}
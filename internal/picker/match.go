@@ -0,0 +1,79 @@
+package picker
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+func Filter(items []Item, query string) []int {
+	query = strings.TrimSpace(query)
+	indexes := make([]int, 0, len(items))
+	if query == "" {
+		for i := range items {
+			indexes = append(indexes, i)
+		}
+		return indexes
+	}
+
+	scores := make(map[int]int, len(items))
+	for i, item := range items {
+		score, ok := Match(query, item.Name)
+		if !ok {
+			// Matches on the description rank below any name match.
+			if score, ok = Match(query, item.Description); !ok {
+				continue
+			}
+			score -= 1000
+		}
+		scores[i] = score
+		indexes = append(indexes, i)
+	}
+
+	sort.SliceStable(indexes, func(a, b int) bool {
+		return scores[indexes[a]] > scores[indexes[b]]
+	})
+	return indexes
+}
+
+// Consecutive runes, word boundaries and matches near the start score higher.
+func Match(query, text string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	t := []rune(strings.ToLower(text))
+	if len(q) == 0 {
+		return 0, true
+	}
+
+	score := 0
+	qi := 0
+	prev := -2
+	for ti, r := range t {
+		if qi == len(q) {
+			break
+		}
+		if r != q[qi] {
+			continue
+		}
+
+		score++
+		if ti == prev+1 {
+			score += 5
+		}
+		if ti == 0 || isBoundary(t[ti-1]) {
+			score += 3
+		}
+		if qi == 0 {
+			score -= ti
+		}
+		prev = ti
+		qi++
+	}
+	if qi < len(q) {
+		return 0, false
+	}
+	return score, true
+}
+
+func isBoundary(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
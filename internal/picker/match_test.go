@@ -0,0 +1,50 @@
+package picker_test
+
+import (
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/picker"
+)
+
+func TestMatch(t *testing.T) {
+	if _, ok := picker.Match("ppay", "prod/payments"); !ok {
+		t.Fatalf("expected subsequence to match")
+	}
+	if _, ok := picker.Match("PROD", "prod/payments"); !ok {
+		t.Fatalf("expected match to ignore case")
+	}
+	if _, ok := picker.Match("pz", "prod/payments"); ok {
+		t.Fatalf("expected missing rune not to match")
+	}
+
+	prefix, _ := picker.Match("pay", "payments")
+	scattered, _ := picker.Match("pay", "prod-east-yard")
+	if prefix <= scattered {
+		t.Fatalf("expected consecutive prefix match (%d) to beat scattered match (%d)", prefix, scattered)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	items := []picker.Item{
+		{Name: "dev", Description: "https://dev.internal"},
+		{Name: "prod/payments", Description: "https://prod.internal"},
+		{Name: "payments-staging", Description: "https://staging.internal"},
+		{Name: "qa", Description: "https://payments-qa.internal"},
+	}
+
+	got := picker.Filter(items, "")
+	if len(got) != len(items) || got[0] != 0 || got[3] != 3 {
+		t.Fatalf("Filter(\"\") = %v, want every item in order", got)
+	}
+
+	got = picker.Filter(items, "pay")
+	want := []int{2, 1, 3}
+	if len(got) != len(want) {
+		t.Fatalf("Filter(pay) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Filter(pay) = %v, want %v", got, want)
+		}
+	}
+}
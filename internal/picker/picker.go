@@ -0,0 +1,273 @@
+package picker
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+var (
+	ErrCanceled       = errors.New("selection canceled")
+	ErrNotInteractive = errors.New("interactive selection requires a terminal")
+)
+
+const defaultHeight = 10
+
+type Item struct {
+	Name        string
+	Description string
+}
+
+type Options struct {
+	Prompt   string
+	Selected int
+	Height   int
+}
+
+func Run(in, out *os.File, items []Item, opts Options) (int, error) {
+	if !term.IsTerminal(int(in.Fd())) || !term.IsTerminal(int(out.Fd())) {
+		return -1, ErrNotInteractive
+	}
+
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return -1, err
+	}
+	defer func() { _ = term.Restore(int(in.Fd()), state) }()
+
+	return Select(in, out, items, opts)
+}
+
+// Select expects in to be unbuffered already.
+func Select(in io.Reader, out io.Writer, items []Item, opts Options) (int, error) {
+	if len(items) == 0 {
+		return -1, errors.New("nothing to choose from")
+	}
+
+	m := newModel(items, opts)
+	reader := bufio.NewReader(in)
+	defer fmt.Fprint(out, "\r\x1b[J")
+
+	for {
+		m.render(out)
+
+		k, err := readKey(reader)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return -1, ErrCanceled
+			}
+			return -1, err
+		}
+
+		switch k.kind {
+		case keyEnter:
+			if chosen := m.chosen(); chosen >= 0 {
+				return chosen, nil
+			}
+		case keyCancel:
+			return -1, ErrCanceled
+		default:
+			m.handle(k)
+		}
+	}
+}
+
+type keyKind int
+
+const (
+	keyRune keyKind = iota
+	keyUp
+	keyDown
+	keyEnter
+	keyCancel
+	keyBackspace
+	keyClear
+	keyIgnored
+)
+
+type key struct {
+	kind keyKind
+	r    rune
+}
+
+func readKey(r *bufio.Reader) (key, error) {
+	c, _, err := r.ReadRune()
+	if err != nil {
+		return key{}, err
+	}
+
+	switch c {
+	case '\r', '\n':
+		return key{kind: keyEnter}, nil
+	case 3, 7: // Ctrl-C, Ctrl-G
+		return key{kind: keyCancel}, nil
+	case 127, 8:
+		return key{kind: keyBackspace}, nil
+	case 21: // Ctrl-U
+		return key{kind: keyClear}, nil
+	case 16: // Ctrl-P
+		return key{kind: keyUp}, nil
+	case 14: // Ctrl-N
+		return key{kind: keyDown}, nil
+	case 27:
+		// A lone Esc cancels; escape sequences arrive in a single read.
+		if r.Buffered() == 0 {
+			return key{kind: keyCancel}, nil
+		}
+		return readEscape(r)
+	}
+
+	if unicode.IsPrint(c) {
+		return key{kind: keyRune, r: c}, nil
+	}
+	return key{kind: keyIgnored}, nil
+}
+
+func readEscape(r *bufio.Reader) (key, error) {
+	next, err := r.ReadByte()
+	if err != nil {
+		return key{}, err
+	}
+	if next != '[' && next != 'O' {
+		return key{kind: keyIgnored}, nil
+	}
+
+	// Consume the rest of the sequence: parameters followed by a final byte.
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return key{}, err
+		}
+		if b >= 0x40 && b <= 0x7e {
+			switch b {
+			case 'A':
+				return key{kind: keyUp}, nil
+			case 'B':
+				return key{kind: keyDown}, nil
+			default:
+				return key{kind: keyIgnored}, nil
+			}
+		}
+	}
+}
+
+type model struct {
+	items    []Item
+	prompt   string
+	height   int
+	query    []rune
+	filtered []int
+	cursor   int
+	offset   int
+}
+
+func newModel(items []Item, opts Options) *model {
+	m := &model{items: items, prompt: opts.Prompt, height: opts.Height}
+	if m.prompt == "" {
+		m.prompt = "> "
+	}
+	if m.height <= 0 {
+		m.height = defaultHeight
+	}
+	m.filter()
+	if opts.Selected > 0 && opts.Selected < len(items) {
+		m.cursor = opts.Selected
+		m.scroll()
+	}
+	return m
+}
+
+func (m *model) handle(k key) {
+	switch k.kind {
+	case keyRune:
+		m.query = append(m.query, k.r)
+		m.filter()
+	case keyBackspace:
+		if len(m.query) > 0 {
+			m.query = m.query[:len(m.query)-1]
+			m.filter()
+		}
+	case keyClear:
+		m.query = nil
+		m.filter()
+	case keyUp:
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case keyDown:
+		if m.cursor < len(m.filtered)-1 {
+			m.cursor++
+		}
+	}
+	m.scroll()
+}
+
+func (m *model) filter() {
+	m.filtered = Filter(m.items, string(m.query))
+	m.cursor = 0
+	m.offset = 0
+}
+
+func (m *model) scroll() {
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.height {
+		m.offset = m.cursor - m.height + 1
+	}
+}
+
+func (m *model) chosen() int {
+	if len(m.filtered) == 0 {
+		return -1
+	}
+	return m.filtered[m.cursor]
+}
+
+func (m *model) render(out io.Writer) {
+	var b strings.Builder
+
+	// The cursor is left on the prompt line, so clearing from there removes
+	// the previous frame.
+	b.WriteString("\r\x1b[J")
+
+	nameWidth := 0
+	for _, idx := range m.filtered {
+		if w := utf8.RuneCountInString(m.items[idx].Name); w > nameWidth {
+			nameWidth = w
+		}
+	}
+
+	end := min(m.offset+m.height, len(m.filtered))
+	lines := 0
+	for i := m.offset; i < end; i++ {
+		item := m.items[m.filtered[i]]
+		b.WriteString("\r\n")
+		lines++
+
+		marker := "  "
+		if i == m.cursor {
+			marker = "\x1b[7m> "
+		}
+		fmt.Fprintf(&b, "%s%-*s", marker, nameWidth, item.Name)
+		if item.Description != "" {
+			fmt.Fprintf(&b, "  \x1b[2m%s\x1b[22m", item.Description)
+		}
+		b.WriteString("\x1b[0m")
+	}
+	b.WriteString("\r\n")
+	fmt.Fprintf(&b, "  \x1b[2m%d/%d\x1b[0m", len(m.filtered), len(m.items))
+	lines++
+
+	// Return to the prompt line and place the cursor after the query.
+	fmt.Fprintf(&b, "\x1b[%dA\r%s%s", lines, m.prompt, string(m.query))
+
+	_, _ = io.WriteString(out, b.String())
+}
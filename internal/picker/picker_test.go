@@ -0,0 +1,66 @@
+package picker_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/picker"
+)
+
+func TestRunSelection(t *testing.T) {
+	items := []picker.Item{
+		{Name: "dev", Description: "https://dev"},
+		{Name: "prod", Description: "https://prod"},
+		{Name: "prod/payments", Description: "https://prod"},
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		opts  picker.Options
+		want  int
+	}{
+		{name: "enter picks first", input: "\r", want: 0},
+		{name: "arrow down", input: "\x1b[B\x1b[B\r", want: 2},
+		{name: "arrow up stops at top", input: "\x1b[A\x1b[B\r", want: 1},
+		{name: "ctrl-n and ctrl-p", input: "\x0e\x0e\x10\r", want: 1},
+		{name: "type to filter", input: "paym\r", want: 2},
+		{name: "backspace widens filter", input: "devx\x7f\r", want: 0},
+		{name: "starts on selected", input: "\r", opts: picker.Options{Selected: 1}, want: 1},
+	}
+
+	for _, tc := range tests {
+		var out bytes.Buffer
+		got, err := picker.Select(strings.NewReader(tc.input), &out, items, tc.opts)
+		if err != nil {
+			t.Fatalf("%s: Select() error = %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: Select() = %d, want %d", tc.name, got, tc.want)
+		}
+		if !strings.Contains(out.String(), "prod/payments") {
+			t.Fatalf("%s: expected items to be rendered, got %q", tc.name, out.String())
+		}
+	}
+}
+
+func TestRunCancel(t *testing.T) {
+	items := []picker.Item{{Name: "dev"}}
+
+	for _, input := range []string{"\x03", "\x1b", ""} {
+		var out bytes.Buffer
+		if _, err := picker.Select(strings.NewReader(input), &out, items, picker.Options{}); !errors.Is(err, picker.ErrCanceled) {
+			t.Fatalf("Select(%q) error = %v, want ErrCanceled", input, err)
+		}
+	}
+}
+
+func TestRunEnterWithoutMatches(t *testing.T) {
+	var out bytes.Buffer
+	_, err := picker.Select(strings.NewReader("zzz\r\x03"), &out, []picker.Item{{Name: "dev"}}, picker.Options{})
+	if !errors.Is(err, picker.ErrCanceled) {
+		t.Fatalf("Select() error = %v, want ErrCanceled after enter with no matches", err)
+	}
+}
@@ -0,0 +1,91 @@
+package probe
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/brianmichel/nomad-context/internal/nomadapi"
+	"github.com/brianmichel/nomad-context/internal/parallel"
+)
+
+type TokenState string
+
+const (
+	TokenValid       TokenState = "valid"
+	TokenInvalid     TokenState = "invalid"
+	TokenNone        TokenState = "none"
+	TokenACLDisabled TokenState = "acl disabled"
+	TokenUnknown     TokenState = "unknown"
+)
+
+type Target struct {
+	Name    string
+	Address string
+	API     nomadapi.Options
+}
+
+type Result struct {
+	Name      string
+	Reachable bool
+	Latency   time.Duration
+	Version   string
+	Leader    string
+	Region    string
+	Token     TokenState
+	Err       error
+}
+
+type Options struct {
+	Parallel int
+	Timeout  time.Duration
+	HTTP     *http.Client
+}
+
+func All(ctx context.Context, targets []Target, opts Options) []Result {
+	return parallel.Map(targets, opts.Parallel, func(target Target) Result {
+		return One(ctx, target, opts)
+	})
+}
+
+func One(ctx context.Context, target Target, opts Options) Result {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	result := Result{Name: target.Name, Token: TokenUnknown}
+	client := nomadapi.New(target.Address, target.API, opts.HTTP)
+
+	start := time.Now()
+	leader, err := client.Leader(ctx)
+	if err != nil && !nomadapi.IsStatusError(err) {
+		result.Err = err
+		return result
+	}
+	result.Reachable = true
+	result.Latency = time.Since(start)
+	result.Leader = leader
+
+	if self, err := client.AgentSelf(ctx); err == nil {
+		result.Version = self.Version()
+		result.Region = self.Config.Region
+	}
+
+	if target.API.Token == "" {
+		result.Token = TokenNone
+		return result
+	}
+
+	_, err = client.TokenSelf(ctx)
+	switch {
+	case err == nil:
+		result.Token = TokenValid
+	case nomadapi.ACLDisabled(err):
+		result.Token = TokenACLDisabled
+	case nomadapi.Forbidden(err):
+		result.Token = TokenInvalid
+	}
+	return result
+}
@@ -0,0 +1,94 @@
+package probe_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/brianmichel/nomad-context/internal/nomadapi"
+	"github.com/brianmichel/nomad-context/internal/probe"
+)
+
+func fakeNomad(inFlight, maxInFlight *int32) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/status/leader", func(w http.ResponseWriter, _ *http.Request) {
+		if inFlight != nil {
+			n := atomic.AddInt32(inFlight, 1)
+			defer atomic.AddInt32(inFlight, -1)
+			for {
+				seen := atomic.LoadInt32(maxInFlight)
+				if n <= seen || atomic.CompareAndSwapInt32(maxInFlight, seen, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		w.Write([]byte(`"10.0.0.1:4647"`))
+	})
+	mux.HandleFunc("/v1/agent/self", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"config": {"Region": "eu", "Version": {"Version": "1.9.3", "VersionPrerelease": ""}}}`))
+	})
+	mux.HandleFunc("/v1/acl/token/self", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Nomad-Token") != "good" {
+			http.Error(w, "Permission denied", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"AccessorID": "abc", "Type": "client"}`))
+	})
+	return mux
+}
+
+func TestAllReportsClusterState(t *testing.T) {
+	srv := httptest.NewServer(fakeNomad(nil, nil))
+	defer srv.Close()
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+
+	targets := []probe.Target{
+		{Name: "good", Address: srv.URL, API: nomadapi.Options{Token: "good"}},
+		{Name: "bad-token", Address: srv.URL, API: nomadapi.Options{Token: "bad"}},
+		{Name: "anonymous", Address: srv.URL},
+		{Name: "down", Address: downURL, API: nomadapi.Options{Token: "good"}},
+	}
+
+	results := probe.All(context.Background(), targets, probe.Options{Parallel: 2, Timeout: time.Second})
+	if len(results) != len(targets) {
+		t.Fatalf("expected %d results, got %d", len(targets), len(results))
+	}
+
+	good := results[0]
+	if good.Name != "good" || !good.Reachable || good.Version != "1.9.3" || good.Region != "eu" || good.Leader != "10.0.0.1:4647" || good.Token != probe.TokenValid {
+		t.Fatalf("unexpected result for good: %+v", good)
+	}
+	if results[1].Token != probe.TokenInvalid {
+		t.Fatalf("bad-token token state = %q, want invalid", results[1].Token)
+	}
+	if results[2].Token != probe.TokenNone {
+		t.Fatalf("anonymous token state = %q, want none", results[2].Token)
+	}
+	if results[3].Reachable || results[3].Err == nil {
+		t.Fatalf("expected down to be unreachable, got %+v", results[3])
+	}
+}
+
+func TestAllBoundsParallelism(t *testing.T) {
+	var inFlight, maxInFlight int32
+	srv := httptest.NewServer(fakeNomad(&inFlight, &maxInFlight))
+	defer srv.Close()
+
+	targets := make([]probe.Target, 10)
+	for i := range targets {
+		targets[i] = probe.Target{Name: "ctx", Address: srv.URL}
+	}
+
+	probe.All(context.Background(), targets, probe.Options{Parallel: 3, Timeout: time.Second})
+
+	if got := atomic.LoadInt32(&maxInFlight); got > 3 {
+		t.Fatalf("observed %d concurrent probes, want at most 3", got)
+	}
+}
@@ -0,0 +1,104 @@
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const FileName = ".nomad-context"
+
+type File struct {
+	Path      string
+	Context   string
+	Namespace string
+	Region    string
+}
+
+func Find(dir string) (*File, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		path := filepath.Join(dir, FileName)
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			return Parse(path, data)
+		case errors.Is(err, os.ErrNotExist):
+		default:
+			info, statErr := os.Stat(path)
+			if statErr != nil || !info.IsDir() {
+				return nil, err
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// The file holds either a bare context name or "key = value" lines; # starts
+// a comment.
+func Parse(path string, data []byte) (*File, error) {
+	file := &File{Path: path}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	entries := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries++
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			if entries > 1 {
+				return nil, fmt.Errorf("%s:%d: expected key = value", path, lineNo)
+			}
+			file.Context = line
+			continue
+		}
+
+		key = strings.TrimSpace(key)
+		if i := strings.Index(value, " #"); i >= 0 {
+			value = value[:i]
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if value == "" {
+			return nil, fmt.Errorf("%s:%d: empty value for %q", path, lineNo, key)
+		}
+
+		switch key {
+		case "context":
+			file.Context = value
+		case "namespace":
+			file.Namespace = value
+		case "region":
+			file.Region = value
+		default:
+			return nil, fmt.Errorf("%s:%d: unknown key %q", path, lineNo, key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if file.Context == "" {
+		return nil, fmt.Errorf("%s: no context specified", path)
+	}
+
+	return file, nil
+}
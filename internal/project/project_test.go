@@ -0,0 +1,72 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/project"
+)
+
+func TestFindWalksUpToParent(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "services", "api")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+
+	contents := "# pinned cluster\ncontext = prod\nnamespace = payments # team namespace\nregion = \"eu\"\n"
+	path := filepath.Join(root, project.FileName)
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	file, err := project.Find(nested)
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if file == nil {
+		t.Fatalf("expected project file to be found")
+	}
+	if file.Path != path {
+		t.Fatalf("Path = %q, want %q", file.Path, path)
+	}
+	if file.Context != "prod" || file.Namespace != "payments" || file.Region != "eu" {
+		t.Fatalf("unexpected project file: %+v", file)
+	}
+}
+
+func TestFindWithoutFile(t *testing.T) {
+	file, err := project.Find(t.TempDir())
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if file != nil {
+		t.Fatalf("expected no project file, got %+v", file)
+	}
+}
+
+func TestParseBareContextName(t *testing.T) {
+	file, err := project.Parse("test", []byte("staging\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if file.Context != "staging" {
+		t.Fatalf("Context = %q, want %q", file.Context, "staging")
+	}
+}
+
+func TestParseRejectsInvalidFiles(t *testing.T) {
+	cases := map[string]string{
+		"unknown key":    "context = prod\ncolor = blue\n",
+		"missing":        "namespace = payments\n",
+		"two bare names": "prod\nstaging\n",
+		"empty value":    "context =\n",
+	}
+
+	for name, contents := range cases {
+		if _, err := project.Parse("test", []byte(contents)); err == nil {
+			t.Fatalf("%s: expected Parse() to fail", name)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package prompt
+
+import "fmt"
+
+var InitShells = []string{"bash", "zsh", "fish", "starship"}
+
+const bashInit = `# nomad-context prompt segment, e.g. in ~/.bashrc
+__nomad_context_ps1() {
+  nomad-context prompt --shell bash 2>/dev/null
+}
+PS1='$(__nomad_context_ps1) '"$PS1"
+`
+
+const zshInit = `# nomad-context prompt segment, e.g. in ~/.zshrc
+setopt PROMPT_SUBST
+__nomad_context_ps1() {
+  nomad-context prompt --shell zsh 2>/dev/null
+}
+PROMPT='$(__nomad_context_ps1) '"$PROMPT"
+`
+
+const fishInit = `# nomad-context prompt segment, e.g. in ~/.config/fish/config.fish
+if not functions -q __nomad_context_original_prompt
+    functions -c fish_prompt __nomad_context_original_prompt
+    function fish_prompt
+        set -l segment (nomad-context prompt --shell fish 2>/dev/null)
+        test -n "$segment"; and echo -n "$segment "
+        __nomad_context_original_prompt
+    end
+end
+`
+
+const starshipInit = `# nomad-context prompt module for ~/.config/starship.toml; add
+# ${custom.nomad_context} to your format to place it explicitly.
+[custom.nomad_context]
+command = "nomad-context prompt --no-color --format '{context}'"
+when = true
+symbol = "⎈ "
+style = "bold blue"
+format = "[$symbol$output]($style) "
+shell = ["sh"]
+`
+
+func Init(shell string) (string, error) {
+	switch shell {
+	case "bash":
+		return bashInit, nil
+	case "zsh":
+		return zshInit, nil
+	case "fish":
+		return fishInit, nil
+	case "starship":
+		return starshipInit, nil
+	default:
+		return "", fmt.Errorf("unsupported shell %q (want bash, zsh, fish or starship)", shell)
+	}
+}
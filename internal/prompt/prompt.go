@@ -0,0 +1,146 @@
+package prompt
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultFormat = "{symbol} {context}"
+	DefaultSymbol = "⎈"
+)
+
+// Shells whose prompts need color escapes wrapped so line editing does not
+// count them as printed characters.
+const (
+	ShellPlain = ""
+	ShellBash  = "bash"
+	ShellZsh   = "zsh"
+	ShellFish  = "fish"
+)
+
+var colorCodes = map[string]string{
+	"black":   "30",
+	"red":     "31",
+	"green":   "32",
+	"yellow":  "33",
+	"blue":    "34",
+	"magenta": "35",
+	"cyan":    "36",
+	"white":   "37",
+	"gray":    "90",
+}
+
+type Segment struct {
+	Context   string
+	Namespace string
+	Region    string
+	Source    string
+}
+
+type Options struct {
+	Format string
+	Symbol string
+	Colors map[string]string
+	Shell  string
+}
+
+// Placeholders are {symbol}, {context}, {namespace}, {region} and {source}.
+func Render(seg Segment, opts Options) (string, error) {
+	format := opts.Format
+	if format == "" {
+		format = DefaultFormat
+	}
+	symbol := opts.Symbol
+	if symbol == "" {
+		symbol = DefaultSymbol
+	}
+	values := map[string]string{
+		"symbol":    symbol,
+		"context":   seg.Context,
+		"namespace": seg.Namespace,
+		"region":    seg.Region,
+		"source":    seg.Source,
+	}
+
+	var b strings.Builder
+	rest := format
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			b.WriteString(rest)
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated placeholder in format %q", format)
+		}
+		name := rest[start+1 : start+end]
+		value, ok := values[name]
+		if !ok {
+			return "", fmt.Errorf("unknown placeholder {%s} (want %s)", name, strings.Join(placeholders(values), ", "))
+		}
+
+		if opts.Shell == ShellZsh {
+			value = strings.ReplaceAll(value, "%", "%%")
+		}
+
+		b.WriteString(rest[:start])
+		code, err := colorCode(opts.Colors[name])
+		if err != nil {
+			return "", err
+		}
+		if code == "" || value == "" {
+			b.WriteString(value)
+		} else {
+			b.WriteString(wrap("\x1b["+code+"m", opts.Shell))
+			b.WriteString(value)
+			b.WriteString(wrap("\x1b[0m", opts.Shell))
+		}
+		rest = rest[start+end+1:]
+	}
+	return b.String(), nil
+}
+
+func colorCode(color string) (string, error) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if color == "" || color == "none" {
+		return "", nil
+	}
+	if code, ok := colorCodes[color]; ok {
+		return code, nil
+	}
+	if n, err := strconv.Atoi(color); err == nil && n >= 0 && n <= 255 {
+		return "38;5;" + color, nil
+	}
+	names := make([]string, 0, len(colorCodes))
+	for name := range colorCodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return "", fmt.Errorf("unknown color %q (want %s, none or 0-255)", color, strings.Join(names, ", "))
+}
+
+func wrap(escape, shell string) string {
+	switch shell {
+	case ShellBash:
+		// Readline's RL_PROMPT_START_IGNORE/END_IGNORE markers; \[ \] are not
+		// interpreted in command substitution output.
+		return "\x01" + escape + "\x02"
+	case ShellZsh:
+		return "%{" + escape + "%}"
+	default:
+		return escape
+	}
+}
+
+func placeholders(values map[string]string) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, "{"+name+"}")
+	}
+	sort.Strings(names)
+	return names
+}
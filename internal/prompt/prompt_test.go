@@ -0,0 +1,97 @@
+package prompt_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/prompt"
+)
+
+func TestRenderDefaultFormat(t *testing.T) {
+	got, err := prompt.Render(prompt.Segment{Context: "prod/payments"}, prompt.Options{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if got != "⎈ prod/payments" {
+		t.Fatalf("Render() = %q", got)
+	}
+}
+
+func TestRenderPlaceholdersAndColors(t *testing.T) {
+	seg := prompt.Segment{Context: "prod", Namespace: "payments", Region: "eu", Source: "project"}
+	opts := prompt.Options{
+		Format: "[{context}:{namespace}@{region}] {source}",
+		Colors: map[string]string{"context": "red", "region": "214"},
+	}
+
+	got, err := prompt.Render(seg, opts)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	want := "[\x1b[31mprod\x1b[0m:payments@\x1b[38;5;214meu\x1b[0m] project"
+	if got != want {
+		t.Fatalf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderWrapsEscapesForShell(t *testing.T) {
+	opts := prompt.Options{Format: "{context}", Colors: map[string]string{"context": "blue"}}
+
+	tests := map[string]string{
+		prompt.ShellBash: "\x01\x1b[34m\x02100%\x01\x1b[0m\x02",
+		prompt.ShellZsh:  "%{\x1b[34m%}100%%%{\x1b[0m%}",
+		prompt.ShellFish: "\x1b[34m100%\x1b[0m",
+	}
+	for shell, want := range tests {
+		opts.Shell = shell
+		got, err := prompt.Render(prompt.Segment{Context: "100%"}, opts)
+		if err != nil {
+			t.Fatalf("Render(%s) error = %v", shell, err)
+		}
+		if got != want {
+			t.Fatalf("Render(%s) = %q, want %q", shell, got, want)
+		}
+	}
+}
+
+func TestRenderSkipsColorForEmptyValues(t *testing.T) {
+	got, err := prompt.Render(prompt.Segment{Context: "dev"}, prompt.Options{
+		Format: "{context}{namespace}",
+		Colors: map[string]string{"namespace": "cyan"},
+	})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if got != "dev" {
+		t.Fatalf("Render() = %q", got)
+	}
+}
+
+func TestRenderRejectsInvalidFormat(t *testing.T) {
+	for _, format := range []string{"{cluster}", "{context"} {
+		if _, err := prompt.Render(prompt.Segment{Context: "dev"}, prompt.Options{Format: format}); err == nil {
+			t.Fatalf("Render(%q) expected error", format)
+		}
+	}
+
+	_, err := prompt.Render(prompt.Segment{Context: "dev"}, prompt.Options{Colors: map[string]string{"context": "teal"}})
+	if err == nil || !strings.Contains(err.Error(), "teal") {
+		t.Fatalf("Render() error = %v, want unknown color", err)
+	}
+}
+
+func TestInit(t *testing.T) {
+	for _, shell := range prompt.InitShells {
+		snippet, err := prompt.Init(shell)
+		if err != nil {
+			t.Fatalf("Init(%s) error = %v", shell, err)
+		}
+		if !strings.Contains(snippet, "nomad-context prompt") {
+			t.Fatalf("Init(%s) snippet does not call the prompt command:\n%s", shell, snippet)
+		}
+	}
+
+	if _, err := prompt.Init("tcsh"); err == nil {
+		t.Fatalf("Init(tcsh) expected error")
+	}
+}
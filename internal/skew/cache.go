@@ -0,0 +1,54 @@
+package skew
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type versionCache struct {
+	Servers map[string]serverEntry `json:"servers"`
+	CLIs    map[string]cliEntry    `json:"clis"`
+}
+
+// Errors are cached too, so an unreachable cluster is not retried until the
+// TTL expires.
+type serverEntry struct {
+	Address   string    `json:"address"`
+	Version   string    `json:"version,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// cliEntry is valid while the binary's size and modification time match.
+type cliEntry struct {
+	Version string    `json:"version"`
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+}
+
+func loadCache(path string) *versionCache {
+	cache := &versionCache{}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, cache)
+	}
+	if cache.Servers == nil {
+		cache.Servers = map[string]serverEntry{}
+	}
+	if cache.CLIs == nil {
+		cache.CLIs = map[string]cliEntry{}
+	}
+	return cache
+}
+
+func (c *versionCache) save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
@@ -0,0 +1,213 @@
+package skew
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/nomadapi"
+)
+
+const (
+	DefaultMinorTolerance = 1
+	DefaultTTL            = time.Hour
+)
+
+var versionPattern = regexp.MustCompile(`v?(\d+)\.(\d+)(?:\.(\d+))?`)
+
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+	Raw   string
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+func ParseVersion(s string) (Version, error) {
+	match := versionPattern.FindStringSubmatch(s)
+	if match == nil {
+		return Version{}, fmt.Errorf("no version found in %q", s)
+	}
+	v := Version{Raw: match[0]}
+	v.Major, _ = strconv.Atoi(match[1])
+	v.Minor, _ = strconv.Atoi(match[2])
+	if match[3] != "" {
+		v.Patch, _ = strconv.Atoi(match[3])
+	}
+	return v, nil
+}
+
+// MinorDistance is -1 when the major versions differ.
+func MinorDistance(a, b Version) int {
+	if a.Major != b.Major {
+		return -1
+	}
+	if a.Minor > b.Minor {
+		return a.Minor - b.Minor
+	}
+	return b.Minor - a.Minor
+}
+
+type Target struct {
+	Context string
+	Address string
+	API     nomadapi.Options
+	Binary  string
+}
+
+type Result struct {
+	CLI    Version
+	Server Version
+	Skewed bool
+}
+
+type Checker struct {
+	Tolerance    int
+	TTL          time.Duration
+	CachePath    string
+	HTTP         *http.Client
+	Now          func() time.Time
+	LocalVersion func(ctx context.Context, binary string) (string, error)
+}
+
+// FromConfig returns nil when the check is disabled.
+func FromConfig(settings *config.VersionCheck) (*Checker, error) {
+	if settings == nil || !settings.Enabled {
+		return nil, nil
+	}
+
+	ttl := DefaultTTL
+	if settings.TTL != "" {
+		parsed, err := time.ParseDuration(settings.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version_check.ttl: %w", err)
+		}
+		ttl = parsed
+	}
+	if settings.MinorTolerance < 0 {
+		return nil, errors.New("version_check.minor_tolerance cannot be negative")
+	}
+
+	dir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Checker{
+		Tolerance: settings.MinorTolerance,
+		TTL:       ttl,
+		CachePath: filepath.Join(dir, "version_cache.json"),
+	}, nil
+}
+
+// The cache is saved even when the server cannot be queried, so a failure
+// is not retried until the TTL expires.
+func (c *Checker) Check(ctx context.Context, target Target) (*Result, error) {
+	cache := loadCache(c.CachePath)
+	now := c.now()
+
+	cli, err := c.cliVersion(ctx, cache, target.Binary)
+	if err != nil {
+		return nil, err
+	}
+	server, serverErr := c.serverVersion(ctx, cache, target, now)
+
+	if err := cache.save(c.CachePath); err != nil {
+		return nil, err
+	}
+	if serverErr != nil {
+		return nil, serverErr
+	}
+
+	distance := MinorDistance(cli, server)
+	return &Result{
+		CLI:    cli,
+		Server: server,
+		Skewed: distance < 0 || distance > c.Tolerance,
+	}, nil
+}
+
+func (c *Checker) cliVersion(ctx context.Context, cache *versionCache, binary string) (Version, error) {
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return Version{}, err
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return Version{}, err
+	}
+
+	if entry, ok := cache.CLIs[path]; ok && entry.Size == info.Size() && entry.ModTime.Equal(info.ModTime()) {
+		return ParseVersion(entry.Version)
+	}
+
+	output, err := c.localVersion(ctx, path)
+	if err != nil {
+		return Version{}, err
+	}
+	v, err := ParseVersion(output)
+	if err != nil {
+		return Version{}, err
+	}
+	cache.CLIs[path] = cliEntry{Version: v.Raw, ModTime: info.ModTime(), Size: info.Size()}
+	return v, nil
+}
+
+func (c *Checker) serverVersion(ctx context.Context, cache *versionCache, target Target, now time.Time) (Version, error) {
+	if entry, ok := cache.Servers[target.Context]; ok && entry.Address == target.Address && now.Sub(entry.CheckedAt) < c.TTL {
+		if entry.Error != "" {
+			return Version{}, fmt.Errorf("cluster version unavailable: %s", entry.Error)
+		}
+		return ParseVersion(entry.Version)
+	}
+
+	entry := serverEntry{Address: target.Address, CheckedAt: now}
+	v, err := c.fetchServerVersion(ctx, target)
+	if err != nil {
+		entry.Error = err.Error()
+	} else {
+		entry.Version = v.Raw
+	}
+	cache.Servers[target.Context] = entry
+	return v, err
+}
+
+func (c *Checker) fetchServerVersion(ctx context.Context, target Target) (Version, error) {
+	self, err := nomadapi.New(target.Address, target.API, c.HTTP).AgentSelf(ctx)
+	if err != nil {
+		return Version{}, err
+	}
+	return ParseVersion(self.Version())
+}
+
+func (c *Checker) localVersion(ctx context.Context, binary string) (string, error) {
+	if c.LocalVersion != nil {
+		return c.LocalVersion(ctx, binary)
+	}
+	output, err := exec.CommandContext(ctx, binary, "version").Output() // #nosec G204 -- binary is the configured nomad executable.
+	if err != nil {
+		return "", fmt.Errorf("%s version: %w", binary, err)
+	}
+	return string(output), nil
+}
+
+func (c *Checker) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
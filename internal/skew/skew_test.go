@@ -0,0 +1,193 @@
+package skew_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/nomadapi"
+	"github.com/brianmichel/nomad-context/internal/skew"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := map[string]skew.Version{
+		"Nomad v1.9.3\nBuildDate 2024-11-11T16:35:41Z\n": {Major: 1, Minor: 9, Patch: 3, Raw: "v1.9.3"},
+		"1.4.7+ent":     {Major: 1, Minor: 4, Patch: 7, Raw: "1.4.7"},
+		"1.10.0-beta.1": {Major: 1, Minor: 10, Patch: 0, Raw: "1.10.0"},
+		"Nomad v2.0":    {Major: 2, Minor: 0, Patch: 0, Raw: "v2.0"},
+	}
+	for input, want := range tests {
+		got, err := skew.ParseVersion(input)
+		if err != nil {
+			t.Fatalf("ParseVersion(%q) error = %v", input, err)
+		}
+		if got != want {
+			t.Fatalf("ParseVersion(%q) = %+v, want %+v", input, got, want)
+		}
+	}
+
+	if _, err := skew.ParseVersion("not a version"); err == nil {
+		t.Fatalf("expected error for input without a version")
+	}
+}
+
+func TestMinorDistance(t *testing.T) {
+	v := func(major, minor int) skew.Version { return skew.Version{Major: major, Minor: minor} }
+
+	if got := skew.MinorDistance(v(1, 9), v(1, 4)); got != 5 {
+		t.Fatalf("MinorDistance(1.9, 1.4) = %d, want 5", got)
+	}
+	if got := skew.MinorDistance(v(1, 4), v(1, 9)); got != 5 {
+		t.Fatalf("MinorDistance(1.4, 1.9) = %d, want 5", got)
+	}
+	if got := skew.MinorDistance(v(2, 0), v(1, 9)); got != -1 {
+		t.Fatalf("MinorDistance(2.0, 1.9) = %d, want -1", got)
+	}
+}
+
+func TestCheckerCachesVersions(t *testing.T) {
+	serverVersion := "1.4.7"
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/v1/agent/self" {
+			t.Errorf("unexpected request path %q", r.URL.Path)
+		}
+		if r.Header.Get("X-Nomad-Token") != "secret" {
+			t.Errorf("expected token header, got %q", r.Header.Get("X-Nomad-Token"))
+		}
+		_, _ = w.Write([]byte(`{"config":{"Version":{"Version":"` + serverVersion + `"}}}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "nomad")
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	localRuns := 0
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	checker := &skew.Checker{
+		Tolerance: 1,
+		TTL:       time.Hour,
+		CachePath: filepath.Join(dir, "cache.json"),
+		Now:       func() time.Time { return now },
+		LocalVersion: func(context.Context, string) (string, error) {
+			localRuns++
+			return "Nomad v1.9.3\n", nil
+		},
+	}
+	target := skew.Target{Context: "legacy", Address: server.URL, API: nomadapi.Options{Token: "secret"}, Binary: binary}
+
+	result, err := checker.Check(context.Background(), target)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !result.Skewed || result.CLI.String() != "1.9.3" || result.Server.String() != "1.4.7" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	serverVersion = "1.9.0"
+	if _, err := checker.Check(context.Background(), target); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if requests != 1 || localRuns != 1 {
+		t.Fatalf("expected cached versions, got %d server requests and %d local runs", requests, localRuns)
+	}
+
+	now = now.Add(2 * time.Hour)
+	result, err = checker.Check(context.Background(), target)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if requests != 2 || result.Skewed {
+		t.Fatalf("expected a refreshed, matching server version, got %d requests and %+v", requests, result)
+	}
+}
+
+func TestCheckerCachesServerFailures(t *testing.T) {
+	forbidden := true
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if forbidden {
+			http.Error(w, "Permission denied", http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"config":{"Version":{"Version":"1.9.1"}}}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "nomad")
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	localRuns := 0
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	checker := &skew.Checker{
+		Tolerance: 1,
+		TTL:       time.Hour,
+		CachePath: filepath.Join(dir, "cache.json"),
+		Now:       func() time.Time { return now },
+		LocalVersion: func(context.Context, string) (string, error) {
+			localRuns++
+			return "Nomad v1.9.3\n", nil
+		},
+	}
+	target := skew.Target{Context: "locked", Address: server.URL, Binary: binary}
+
+	for i := 0; i < 2; i++ {
+		if _, err := checker.Check(context.Background(), target); err == nil {
+			t.Fatalf("Check() expected an error for a forbidden agent endpoint")
+		}
+	}
+	if requests != 1 || localRuns != 1 {
+		t.Fatalf("expected the failure and CLI version to be cached, got %d server requests and %d local runs", requests, localRuns)
+	}
+
+	forbidden = false
+	now = now.Add(2 * time.Hour)
+	result, err := checker.Check(context.Background(), target)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if requests != 2 || localRuns != 1 || result.Skewed {
+		t.Fatalf("expected a retried server check after the TTL, got %d requests, %d local runs and %+v", requests, localRuns, result)
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	t.Setenv("NOMAD_CONTEXT_HOME", t.TempDir())
+
+	checker, err := skew.FromConfig(nil)
+	if err != nil || checker != nil {
+		t.Fatalf("FromConfig(nil) = %v, %v; want disabled", checker, err)
+	}
+
+	checker, err = skew.FromConfig(&config.VersionCheck{Enabled: true, MinorTolerance: 2})
+	if err != nil {
+		t.Fatalf("FromConfig() error = %v", err)
+	}
+	if checker.Tolerance != 2 || checker.TTL != skew.DefaultTTL {
+		t.Fatalf("unexpected checker %+v", checker)
+	}
+
+	if _, err := skew.FromConfig(&config.VersionCheck{Enabled: true, TTL: "soon"}); err == nil {
+		t.Fatalf("expected invalid TTL to be rejected")
+	}
+}
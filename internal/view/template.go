@@ -0,0 +1,61 @@
+package view
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+)
+
+var Funcs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+	// default returns fallback when value is empty: {{.Region | default "global"}}
+	"default": func(fallback string, value any) any {
+		if value == nil || value == "" {
+			return fallback
+		}
+		return value
+	},
+	"keys": func(m map[string]string) []string {
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return keys
+	},
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"yaml": func(v any) (string, error) {
+		data, err := config.Marshal(v, config.FormatYAML)
+		return string(data), err
+	},
+}
+
+func Template(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Funcs(Funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+	return tmpl, nil
+}
+
+func Execute(out io.Writer, text string, data any) error {
+	tmpl, err := Template(text)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(out, data); err != nil {
+		return fmt.Errorf("execute template: %w", err)
+	}
+	return nil
+}
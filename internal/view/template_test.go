@@ -0,0 +1,61 @@
+package view_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/brianmichel/nomad-context/internal/view"
+)
+
+func TestExecute(t *testing.T) {
+	list := []view.Context{
+		{Name: "dev", Address: "https://dev", Current: true, Token: view.Token{Stored: true}},
+		{Name: "prod", Address: "https://prod", Region: "eu", Env: map[string]string{"B": "2", "A": "1"}},
+	}
+
+	tests := []struct {
+		name string
+		text string
+		data any
+		want string
+	}{
+		{
+			name: "range",
+			text: `{{range .}}{{.Name}}={{.Address}}{{"\n"}}{{end}}`,
+			data: list,
+			want: "dev=https://dev\nprod=https://prod\n",
+		},
+		{
+			name: "helpers",
+			text: `{{range .}}{{upper .Name}} {{.Region | default "global"}} {{.Token.Stored}} {{join (keys .Env) ","}};{{end}}`,
+			data: list,
+			want: "DEV global true ;PROD eu false A,B;",
+		},
+		{
+			name: "json",
+			text: `{{json .Token}}`,
+			data: list[0],
+			want: `{"stored":true}`,
+		},
+	}
+
+	for _, tc := range tests {
+		var buf bytes.Buffer
+		if err := view.Execute(&buf, tc.text, tc.data); err != nil {
+			t.Fatalf("%s: Execute() error = %v", tc.name, err)
+		}
+		if buf.String() != tc.want {
+			t.Fatalf("%s: Execute() = %q, want %q", tc.name, buf.String(), tc.want)
+		}
+	}
+}
+
+func TestExecuteErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := view.Execute(&buf, `{{.Name`, view.Context{}); err == nil {
+		t.Fatalf("expected parse error")
+	}
+	if err := view.Execute(&buf, `{{.Secret}}`, view.Context{}); err == nil {
+		t.Fatalf("expected error for unknown field")
+	}
+}
@@ -0,0 +1,122 @@
+package view
+
+import (
+	"time"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/contexts"
+	"github.com/brianmichel/nomad-context/internal/probe"
+)
+
+// Context is the schema for machine-readable output. Fields are only ever
+// added; existing names and meanings do not change.
+type Context struct {
+	Name           string                 `json:"name" yaml:"name"`
+	Current        bool                   `json:"current" yaml:"current"`
+	Parent         string                 `json:"parent,omitempty" yaml:"parent,omitempty"`
+	Parents        []string               `json:"parents,omitempty" yaml:"parents,omitempty"`
+	Address        string                 `json:"address" yaml:"address"`
+	Namespace      string                 `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Region         string                 `json:"region,omitempty" yaml:"region,omitempty"`
+	HTTPAuthUser   string                 `json:"http_auth_user,omitempty" yaml:"http_auth_user,omitempty"`
+	NomadPath      string                 `json:"nomad_path,omitempty" yaml:"nomad_path,omitempty"`
+	NomadVersion   string                 `json:"nomad_version,omitempty" yaml:"nomad_version,omitempty"`
+	Consul         *config.ConsulSettings `json:"consul,omitempty" yaml:"consul,omitempty"`
+	Vault          *config.VaultSettings  `json:"vault,omitempty" yaml:"vault,omitempty"`
+	Env            map[string]string      `json:"env,omitempty" yaml:"env,omitempty"`
+	SecretEnv      []string               `json:"secret_env,omitempty" yaml:"secret_env,omitempty"`
+	Tags           map[string]string      `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Catalog        string                 `json:"catalog,omitempty" yaml:"catalog,omitempty"`
+	CatalogRemoved bool                   `json:"catalog_removed,omitempty" yaml:"catalog_removed,omitempty"`
+	Token          Token                  `json:"token" yaml:"token"`
+	Sources        map[string]string      `json:"sources,omitempty" yaml:"sources,omitempty"`
+	Error          string                 `json:"error,omitempty" yaml:"error,omitempty"`
+	SelectedBy     string                 `json:"selected_by,omitempty" yaml:"selected_by,omitempty"`
+	ProjectFile    string                 `json:"project_file,omitempty" yaml:"project_file,omitempty"`
+	Check          *Check                 `json:"check,omitempty" yaml:"check,omitempty"`
+}
+
+type Token struct {
+	Stored bool   `json:"stored" yaml:"stored"`
+	From   string `json:"from,omitempty" yaml:"from,omitempty"`
+}
+
+type Check struct {
+	Reachable bool   `json:"reachable" yaml:"reachable"`
+	LatencyMS int64  `json:"latency_ms" yaml:"latency_ms"`
+	Version   string `json:"version,omitempty" yaml:"version,omitempty"`
+	Leader    string `json:"leader,omitempty" yaml:"leader,omitempty"`
+	Region    string `json:"region,omitempty" yaml:"region,omitempty"`
+	Token     string `json:"token,omitempty" yaml:"token,omitempty"`
+	Error     string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func FromResolved(r *contexts.Resolved, current bool, tokenFrom string) Context {
+	ctx := r.Context
+	v := Context{
+		Name:           ctx.Name,
+		Current:        current,
+		Parent:         ctx.Parent,
+		Address:        ctx.Address,
+		Namespace:      ctx.Namespace,
+		Region:         ctx.Region,
+		HTTPAuthUser:   ctx.HTTPAuthUser,
+		NomadPath:      ctx.NomadPath,
+		NomadVersion:   ctx.NomadVersion,
+		Consul:         ctx.Consul,
+		Vault:          ctx.Vault,
+		Env:            ctx.Env,
+		SecretEnv:      ctx.SecretEnv,
+		Tags:           ctx.Tags,
+		Catalog:        ctx.Catalog,
+		CatalogRemoved: ctx.CatalogRemoved,
+		Token:          Token{Stored: tokenFrom != ""},
+	}
+	if len(r.Chain) > 1 {
+		v.Parents = r.Chain[1:]
+	}
+	if r.Err != nil {
+		v.Error = r.Err.Error()
+	}
+	if tokenFrom != "" && tokenFrom != ctx.Name {
+		v.Token.From = tokenFrom
+	}
+
+	for field, source := range r.Sources {
+		if source == ctx.Name {
+			continue
+		}
+		if v.Sources == nil {
+			v.Sources = map[string]string{}
+		}
+		v.Sources[field] = source
+	}
+	return v
+}
+
+func FromSelection(sel *contexts.Selection, current bool, tokenFrom string) Context {
+	v := FromResolved(sel.Resolved, current, tokenFrom)
+	v.Namespace = sel.Namespace
+	v.Region = sel.Region
+	v.SelectedBy = string(sel.Source)
+	v.ProjectFile = sel.ProjectFile
+	return v
+}
+
+func (v Context) WithCheck(result probe.Result) Context {
+	check := &Check{
+		Reachable: result.Reachable,
+		Version:   result.Version,
+		Leader:    result.Leader,
+		Region:    result.Region,
+		Token:     string(result.Token),
+	}
+	if result.Reachable {
+		check.LatencyMS = result.Latency.Round(time.Millisecond).Milliseconds()
+	}
+	if result.Err != nil {
+		check.Error = result.Err.Error()
+	}
+	v.Check = check
+	return v
+}
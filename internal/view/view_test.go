@@ -0,0 +1,69 @@
+package view_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalando/go-keyring"
+
+	"github.com/brianmichel/nomad-context/internal/config"
+	"github.com/brianmichel/nomad-context/internal/contexts"
+	"github.com/brianmichel/nomad-context/internal/probe"
+	"github.com/brianmichel/nomad-context/internal/view"
+)
+
+func TestFromResolved(t *testing.T) {
+	t.Setenv("NOMAD_CONTEXT_HOME", t.TempDir())
+	keyring.MockInit()
+	mgr := contexts.NewManager()
+
+	if err := mgr.Put(&config.Context{Name: "prod", Address: "https://prod", Region: "eu"}, "prod-token"); err != nil {
+		t.Fatalf("Put(prod) error = %v", err)
+	}
+	if err := mgr.Put(&config.Context{Name: "prod/web", Parent: "prod", Namespace: "web"}, ""); err != nil {
+		t.Fatalf("Put(prod/web) error = %v", err)
+	}
+
+	resolved, err := mgr.Resolved("prod/web")
+	if err != nil {
+		t.Fatalf("Resolved() error = %v", err)
+	}
+
+	v := view.FromResolved(resolved, false, "prod")
+	if v.Address != "https://prod" || v.Region != "eu" || v.Namespace != "web" {
+		t.Fatalf("expected resolved values, got %+v", v)
+	}
+	if !v.Token.Stored || v.Token.From != "prod" {
+		t.Fatalf("unexpected token view %+v", v.Token)
+	}
+	if len(v.Parents) != 1 || v.Parents[0] != "prod" {
+		t.Fatalf("unexpected parents %v", v.Parents)
+	}
+	if v.Sources["address"] != "prod" || v.Sources["namespace"] != "" {
+		t.Fatalf("expected only inherited fields in sources, got %v", v.Sources)
+	}
+
+	v = v.WithCheck(probe.Result{Name: "prod/web", Reachable: true, Latency: 12 * time.Millisecond, Version: "1.9.3", Token: probe.TokenValid})
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	for _, want := range []string{`"current":false`, `"token":{"stored":true,"from":"prod"}`, `"latency_ms":12`} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("expected %s in %s", want, data)
+		}
+	}
+	if strings.Contains(string(data), "prod-token") {
+		t.Fatalf("token value leaked into %s", data)
+	}
+}
+
+func TestWithCheckUnreachable(t *testing.T) {
+	v := view.Context{Name: "dev"}.WithCheck(probe.Result{Name: "dev", Err: errors.New("connection refused")})
+	if v.Check == nil || v.Check.Reachable || v.Check.Error != "connection refused" {
+		t.Fatalf("unexpected check %+v", v.Check)
+	}
+}